@@ -2,6 +2,7 @@ package gopython
 
 import (
 	"fmt"
+	"strings"
 	"unsafe"
 
 	"github.com/ebitengine/purego"
@@ -10,79 +11,236 @@ import (
 // registerPythonFunctions registers all CPython API functions with purego
 func (py *PureGoPython) registerPythonFunctions() error {
 	// Core interpreter functions
-	purego.RegisterLibFunc(&py.pyInitialize, py.libHandle, "Py_Initialize")
-	purego.RegisterLibFunc(&py.pyFinalizeEx, py.libHandle, "Py_FinalizeEx")
-	purego.RegisterLibFunc(&py.pyIsInitialized, py.libHandle, "Py_IsInitialized")
-	purego.RegisterLibFunc(&py.pySetProgramName, py.libHandle, "Py_SetProgramName")
-	purego.RegisterLibFunc(&py.pySetPythonHome, py.libHandle, "Py_SetPythonHome")
-	purego.RegisterLibFunc(&py.pySetPath, py.libHandle, "Py_SetPath")
+	py.registerLibFunc(&py.pyInitialize, "Py_Initialize")
+	py.registerLibFunc(&py.pyFinalizeEx, "Py_FinalizeEx")
+	py.registerLibFunc(&py.pyIsInitialized, "Py_IsInitialized")
+	py.registerLibFunc(&py.pySetProgramName, "Py_SetProgramName")
+	py.registerLibFunc(&py.pySetPythonHome, "Py_SetPythonHome")
+	py.registerLibFunc(&py.pySetPath, "Py_SetPath")
 
 	// Code execution functions
-	purego.RegisterLibFunc(&py.pyRunSimpleString, py.libHandle, "PyRun_SimpleString")
+	py.registerLibFunc(&py.pyRunSimpleString, "PyRun_SimpleString")
+	py.registerLibFunc(&py.pyRunStringFlags, "PyRun_StringFlags")
+	py.registerLibFunc(&py.pyCompileString, "Py_CompileString")
 
 	// Module and import functions
-	purego.RegisterLibFunc(&py.pyImportImport, py.libHandle, "PyImport_Import")
-	purego.RegisterLibFunc(&py.pyImportAddModule, py.libHandle, "PyImport_AddModule")
-	purego.RegisterLibFunc(&py.pyModuleGetDict, py.libHandle, "PyModule_GetDict")
-	purego.RegisterLibFunc(&py.pyDictGetItemString, py.libHandle, "PyDict_GetItemString")
+	py.registerLibFunc(&py.pyImportImport, "PyImport_Import")
+	py.registerLibFunc(&py.pyImportAddModule, "PyImport_AddModule")
+	py.registerLibFunc(&py.pyModuleGetDict, "PyModule_GetDict")
+	py.registerLibFunc(&py.pyImportGetModuleDict, "PyImport_GetModuleDict")
+	py.registerLibFunc(&py.pyDictGetItemString, "PyDict_GetItemString")
 
 	// Object attribute functions
-	purego.RegisterLibFunc(&py.pyObjectGetAttr, py.libHandle, "PyObject_GetAttr")
-	purego.RegisterLibFunc(&py.pyObjectCallObject, py.libHandle, "PyObject_CallObject")
-	purego.RegisterLibFunc(&py.pyObjectType, py.libHandle, "PyObject_Type")
-	purego.RegisterLibFunc(&py.pyObjectStr, py.libHandle, "PyObject_Str")
-	purego.RegisterLibFunc(&py.pyObjectRepr, py.libHandle, "PyObject_Repr")
+	py.registerLibFunc(&py.pyObjectGetAttr, "PyObject_GetAttr")
+	py.registerLibFunc(&py.pyObjectGetAttrString, "PyObject_GetAttrString")
+	py.registerLibFunc(&py.pyObjectSetAttrString, "PyObject_SetAttrString")
+	py.registerLibFunc(&py.pyObjectHasAttrString, "PyObject_HasAttrString")
+	py.registerLibFunc(&py.pyObjectIsTrue, "PyObject_IsTrue")
+	py.registerLibFunc(&py.pyObjectCheckBuffer, "PyObject_CheckBuffer")
+	py.registerLibFunc(&py.pyObjectGetBuffer, "PyObject_GetBuffer")
+	py.registerLibFunc(&py.pyBufferRelease, "PyBuffer_Release")
+	py.registerLibFunc(&py.pyCallableCheck, "PyCallable_Check")
+	py.registerLibFunc(&py.pyObjectCallObject, "PyObject_CallObject")
+	py.registerLibFunc(&py.pyObjectCall, "PyObject_Call")
+	py.registerLibFunc(&py.pyObjectType, "PyObject_Type")
+	py.registerLibFunc(&py.pyObjectStr, "PyObject_Str")
+	py.registerLibFunc(&py.pyObjectRepr, "PyObject_Repr")
 
 	// String/Unicode functions
-	purego.RegisterLibFunc(&py.pyUnicodeFromString, py.libHandle, "PyUnicode_FromString")
-	purego.RegisterLibFunc(&py.pyUnicodeAsUTF8, py.libHandle, "PyUnicode_AsUTF8")
+	py.registerLibFunc(&py.pyUnicodeFromString, "PyUnicode_FromString")
+	py.registerLibFunc(&py.pyUnicodeFromStringAndSize, "PyUnicode_FromStringAndSize")
+	py.registerLibFunc(&py.pyUnicodeAsUTF8, "PyUnicode_AsUTF8")
+	py.registerLibFunc(&py.pyUnicodeAsUTF8AndSize, "PyUnicode_AsUTF8AndSize")
+	py.registerLibFunc(&py.pyUnicodeAsEncodedString, "PyUnicode_AsEncodedString")
+	py.registerLibFunc(&py.pyUnicodeDecodeUTF8, "PyUnicode_DecodeUTF8")
+
+	// Bytes functions
+	py.registerLibFunc(&py.pyBytesFromStringAndSize, "PyBytes_FromStringAndSize")
+	py.registerLibFunc(&py.pyBytesAsStringAndSize, "PyBytes_AsStringAndSize")
+	py.registerLibFunc(&py.pyBytesSize, "PyBytes_Size")
+	py.registerLibFunc(&py.pyByteArrayFromStringAndSize, "PyByteArray_FromStringAndSize")
+	py.registerLibFunc(&py.pyByteArrayAsString, "PyByteArray_AsString")
+	py.registerLibFunc(&py.pyByteArraySize, "PyByteArray_Size")
 
 	// Integer functions
-	purego.RegisterLibFunc(&py.pyLongFromLong, py.libHandle, "PyLong_FromLong")
-	purego.RegisterLibFunc(&py.pyLongAsLong, py.libHandle, "PyLong_AsLong")
-	purego.RegisterLibFunc(&py.pyLongFromSize, py.libHandle, "PyLong_FromSize_t")
-	purego.RegisterLibFunc(&py.pyBoolFromLong, py.libHandle, "PyBool_FromLong")
+	py.registerLibFunc(&py.pyLongFromLong, "PyLong_FromLong")
+	py.registerLibFunc(&py.pyLongFromUnsignedLongLong, "PyLong_FromUnsignedLongLong")
+	py.registerLibFunc(&py.pyLongAsLong, "PyLong_AsLong")
+	py.registerLibFunc(&py.pyLongFromString, "PyLong_FromString")
+	py.registerLibFunc(&py.pyLongFromSize, "PyLong_FromSize_t")
+	py.registerLibFunc(&py.pyBoolFromLong, "PyBool_FromLong")
 
 	// Float functions
-	purego.RegisterLibFunc(&py.pyFloatFromDouble, py.libHandle, "PyFloat_FromDouble")
-	purego.RegisterLibFunc(&py.pyFloatAsDouble, py.libHandle, "PyFloat_AsDouble")
+	py.registerLibFunc(&py.pyFloatFromDouble, "PyFloat_FromDouble")
+	py.registerLibFunc(&py.pyFloatAsDouble, "PyFloat_AsDouble")
+
+	// Complex functions
+	py.registerLibFunc(&py.pyComplexFromDoubles, "PyComplex_FromDoubles")
+	py.registerLibFunc(&py.pyComplexRealAsDouble, "PyComplex_RealAsDouble")
+	py.registerLibFunc(&py.pyComplexImagAsDouble, "PyComplex_ImagAsDouble")
+
+	// Number protocol functions
+	py.registerLibFunc(&py.pyNumberIndex, "PyNumber_Index")
+	py.registerLibFunc(&py.pyNumberFloat, "PyNumber_Float")
 
 	// List functions
-	purego.RegisterLibFunc(&py.pyListNew, py.libHandle, "PyList_New")
-	purego.RegisterLibFunc(&py.pyListSetItem, py.libHandle, "PyList_SetItem")
-	purego.RegisterLibFunc(&py.pyListGetItem, py.libHandle, "PyList_GetItem")
-	purego.RegisterLibFunc(&py.pyListSize, py.libHandle, "PyList_Size")
+	py.registerLibFunc(&py.pyListNew, "PyList_New")
+	py.registerLibFunc(&py.pyListSetItem, "PyList_SetItem")
+	py.registerLibFunc(&py.pyListGetItem, "PyList_GetItem")
+	py.registerLibFunc(&py.pyListSize, "PyList_Size")
 
 	// Dictionary functions
-	purego.RegisterLibFunc(&py.pyDictNew, py.libHandle, "PyDict_New")
-	purego.RegisterLibFunc(&py.pyDictSetItemString, py.libHandle, "PyDict_SetItemString")
-	purego.RegisterLibFunc(&py.pyDictKeys, py.libHandle, "PyDict_Keys")
+	py.registerLibFunc(&py.pyDictNew, "PyDict_New")
+	py.registerLibFunc(&py.pyDictSetItemString, "PyDict_SetItemString")
+	py.registerLibFunc(&py.pyDictKeys, "PyDict_Keys")
+	py.registerLibFunc(&py.pyDictGetItem, "PyDict_GetItem")
+	py.registerLibFunc(&py.pyDictSetItem, "PyDict_SetItem")
+	py.registerLibFunc(&py.pyDictDelItemString, "PyDict_DelItemString")
 
 	// Tuple functions
-	purego.RegisterLibFunc(&py.pyTupleNew, py.libHandle, "PyTuple_New")
-	purego.RegisterLibFunc(&py.pyTupleSetItem, py.libHandle, "PyTuple_SetItem")
-	purego.RegisterLibFunc(&py.pyTupleGetItem, py.libHandle, "PyTuple_GetItem")
-	purego.RegisterLibFunc(&py.pyTupleSize, py.libHandle, "PyTuple_Size")
+	py.registerLibFunc(&py.pyTupleNew, "PyTuple_New")
+	py.registerLibFunc(&py.pyTupleSetItem, "PyTuple_SetItem")
+	py.registerLibFunc(&py.pyTupleGetItem, "PyTuple_GetItem")
+	py.registerLibFunc(&py.pyTupleSize, "PyTuple_Size")
+
+	// Set functions
+	py.registerLibFunc(&py.pySetNew, "PySet_New")
+	py.registerLibFunc(&py.pySetAdd, "PySet_Add")
+	py.registerLibFunc(&py.pySetContains, "PySet_Contains")
+	py.registerLibFunc(&py.pyFrozenSetNew, "PyFrozenSet_New")
+
+	// Iterator functions
+	py.registerLibFunc(&py.pyObjectGetIter, "PyObject_GetIter")
+	py.registerLibFunc(&py.pyIterNext, "PyIter_Next")
 
 	// Type checking functions - Note: PyType_GetName only available in Python 3.11+
 	// We'll use an alternative approach for Python 3.10 compatibility
 
+	// Callable creation functions
+	py.registerLibFunc(&py.pyCFunctionNewEx, "PyCFunction_NewEx")
+
 	// Reference counting functions
-	purego.RegisterLibFunc(&py.pyIncRef, py.libHandle, "Py_IncRef")
-	purego.RegisterLibFunc(&py.pyDecRef, py.libHandle, "Py_DecRef")
+	py.registerLibFunc(&py.pyIncRef, "Py_IncRef")
+	py.registerLibFunc(&py.pyDecRef, "Py_DecRef")
 
 	// Error handling functions
-	purego.RegisterLibFunc(&py.pyErrOccurred, py.libHandle, "PyErr_Occurred")
-	purego.RegisterLibFunc(&py.pyErrFetch, py.libHandle, "PyErr_Fetch")
-	purego.RegisterLibFunc(&py.pyErrClear, py.libHandle, "PyErr_Clear")
+	py.registerLibFunc(&py.pyErrOccurred, "PyErr_Occurred")
+	py.registerLibFunc(&py.pyErrFetch, "PyErr_Fetch")
+	py.registerLibFunc(&py.pyErrNormalizeException, "PyErr_NormalizeException")
+	py.registerLibFunc(&py.pyErrClear, "PyErr_Clear")
+	py.registerLibFunc(&py.pyErrSetString, "PyErr_SetString")
+
+	// GIL functions, used by SetTrueGIL, context cancellation and WrapChannel
+	py.registerLibFunc(&py.pyGILStateEnsure, "PyGILState_Ensure")
+	py.registerLibFunc(&py.pyGILStateRelease, "PyGILState_Release")
+	py.registerLibFunc(&py.pyEvalSaveThread, "PyEval_SaveThread")
+	py.registerLibFunc(&py.pyEvalRestoreThread, "PyEval_RestoreThread")
+
+	// Thread functions
+	py.registerLibFunc(&py.pyThreadGetThreadIdent, "PyThread_get_thread_ident")
+	py.registerLibFunc(&py.pyThreadStateSetAsyncExc, "PyThreadState_SetAsyncExc")
+
+	// Sub-interpreter functions
+	py.registerLibFunc(&py.pyNewInterpreter, "Py_NewInterpreter")
+	py.registerLibFunc(&py.pyEndInterpreter, "Py_EndInterpreter")
+	py.registerLibFunc(&py.pyThreadStateGet, "PyThreadState_Get")
+	py.registerLibFunc(&py.pyThreadStateSwap, "PyThreadState_Swap")
+
+	// Global objects exported as data symbols
+	py.pyNone = py.lookupDataSymbol("_Py_NoneStruct")
+	py.pyTrue = py.lookupDataSymbol("_Py_TrueStruct")
+	py.pyFalse = py.lookupDataSymbol("_Py_FalseStruct")
+	py.pyExcRuntimeError = py.lookupObjectPointer("PyExc_RuntimeError")
+	if py.pyExcKeyboardInterrupt = py.lookupObjectPointer("PyExc_KeyboardInterrupt"); py.pyExcKeyboardInterrupt == 0 {
+		py.missingSymbols = append(py.missingSymbols, "PyExc_KeyboardInterrupt")
+	}
+
+	return nil
+}
+
+// optionalSymbols maps the libpython functions that only individual features
+// need to the feature's name. NewPureGoPython succeeds without them and the
+// feature reports ErrFeatureUnavailable when used instead.
+var optionalSymbols = map[string]string{
+	"Py_SetProgramName":             "path configuration",
+	"Py_SetPythonHome":              "path configuration",
+	"Py_SetPath":                    "path configuration",
+	"PyObject_CheckBuffer":          "buffer protocol",
+	"PyObject_GetBuffer":            "buffer protocol",
+	"PyBuffer_Release":              "buffer protocol",
+	"PyLong_FromUnsignedLongLong":   "unsigned integers",
+	"PyComplex_FromDoubles":         "complex numbers",
+	"PyComplex_RealAsDouble":        "complex numbers",
+	"PyComplex_ImagAsDouble":        "complex numbers",
+	"PyByteArray_FromStringAndSize": "bytearray",
+	"PyByteArray_AsString":          "bytearray",
+	"PyByteArray_Size":              "bytearray",
+	"PySet_New":                     "sets",
+	"PySet_Add":                     "sets",
+	"PySet_Contains":                "sets",
+	"PyFrozenSet_New":               "sets",
+	"PyCFunction_NewEx":             "callbacks",
+	"PyGILState_Ensure":             "true GIL management",
+	"PyGILState_Release":            "true GIL management",
+	"PyThreadState_SetAsyncExc":     "cancellation",
+	"PyExc_KeyboardInterrupt":       "cancellation",
+	"Py_NewInterpreter":             "sub-interpreters",
+	"Py_EndInterpreter":             "sub-interpreters",
+	"PyThreadState_Get":             "sub-interpreters",
+	"PyThreadState_Swap":            "sub-interpreters",
+}
 
-	// GIL functions (for future use if needed)
-	purego.RegisterLibFunc(&py.pyGILStateEnsure, py.libHandle, "PyGILState_Ensure")
-	purego.RegisterLibFunc(&py.pyGILStateRelease, py.libHandle, "PyGILState_Release")
+// registerLibFunc binds fptr to the named libpython function. A symbol the
+// library doesn't export is recorded in missingSymbols and leaves fptr nil
+// instead of panicking like purego.RegisterLibFunc.
+func (py *PureGoPython) registerLibFunc(fptr interface{}, name string) {
+	sym, err := purego.Dlsym(py.libHandle, name)
+	if err != nil || sym == 0 {
+		py.missingSymbols = append(py.missingSymbols, name)
+		return
+	}
+	purego.RegisterFunc(fptr, sym)
+}
 
+// requireFeature returns an error naming the first missing symbol the
+// feature depends on, or nil if all of them were registered
+func (py *PureGoPython) requireFeature(feature string) error {
+	for _, name := range py.missingSymbols {
+		if optionalSymbols[name] == feature {
+			return fmt.Errorf("%w: feature %s unavailable: symbol %s not found", ErrFeatureUnavailable, feature, name)
+		}
+	}
 	return nil
 }
 
+// MissingSymbols returns the libpython functions that could not be found when
+// the library was loaded, such as PyComplex_RealAsDouble in a stripped build.
+// Features depending on them return ErrFeatureUnavailable.
+func (py *PureGoPython) MissingSymbols() []string {
+	return append([]string(nil), py.missingSymbols...)
+}
+
+// lookupDataSymbol returns the address of a data symbol exported by libpython,
+// or 0 if it is not available
+func (py *PureGoPython) lookupDataSymbol(name string) uintptr {
+	addr, err := purego.Dlsym(py.libHandle, name)
+	if err != nil {
+		return 0
+	}
+	return addr
+}
+
+// lookupObjectPointer returns the PyObject* stored in a global variable
+// exported by libpython (such as the PyExc_* exception types), or 0 if the
+// symbol is not available
+func (py *PureGoPython) lookupObjectPointer(name string) uintptr {
+	addr := py.lookupDataSymbol(name)
+	if addr == 0 {
+		return 0
+	}
+	return **(**uintptr)(unsafe.Pointer(&addr))
+}
+
 // Type checking helper functions using runtime type inspection
 // These replace the macro-based type checking that caused undefined symbol errors
 
@@ -144,10 +302,33 @@ func (py *PureGoPython) isInt(obj PyObject) bool {
 	return typeName == "int"
 }
 
-// isBool checks if a Python object is a boolean
+// isBool checks if a Python object is a boolean: Py_True or Py_False by
+// identity, or a bool-like scalar such as numpy.bool_, which does not
+// subclass bool
 func (py *PureGoPython) isBool(obj PyObject) bool {
+	if obj != 0 && (uintptr(obj) == py.pyTrue || uintptr(obj) == py.pyFalse) {
+		return true
+	}
 	typeName := py.getTypeName(obj)
-	return typeName == "bool"
+	return typeName == "bool" || typeName == "bool_"
+}
+
+// pythonBoolToGo converts a boolean accepted by isBool to a Go bool. Bool-like
+// types go through their truth value rather than PyLong_AsLong, since they
+// are not necessarily ints.
+func (py *PureGoPython) pythonBoolToGo(obj PyObject) (bool, error) {
+	switch uintptr(obj) {
+	case py.pyTrue:
+		return true, nil
+	case py.pyFalse:
+		return false, nil
+	}
+
+	truth := py.pyObjectIsTrue(uintptr(obj))
+	if truth < 0 {
+		return false, fmt.Errorf("failed to convert %s to bool: %w", py.getTypeName(obj), py.getPythonError())
+	}
+	return truth != 0, nil
 }
 
 // isFloat checks if a Python object is a float
@@ -156,6 +337,71 @@ func (py *PureGoPython) isFloat(obj PyObject) bool {
 	return typeName == "float"
 }
 
+// isComplex checks if a Python object is a complex number
+func (py *PureGoPython) isComplex(obj PyObject) bool {
+	typeName := py.getTypeName(obj)
+	return typeName == "complex"
+}
+
+// isDatetime checks if a Python object is a datetime.datetime
+func (py *PureGoPython) isDatetime(obj PyObject) bool {
+	typeName := py.getTypeName(obj)
+	return typeName == "datetime"
+}
+
+// isDate checks if a Python object is a datetime.date
+func (py *PureGoPython) isDate(obj PyObject) bool {
+	typeName := py.getTypeName(obj)
+	return typeName == "date"
+}
+
+// isTimeOfDay checks if a Python object is a datetime.time
+func (py *PureGoPython) isTimeOfDay(obj PyObject) bool {
+	typeName := py.getTypeName(obj)
+	return typeName == "time"
+}
+
+// isTimedelta checks if a Python object is a datetime.timedelta
+func (py *PureGoPython) isTimedelta(obj PyObject) bool {
+	typeName := py.getTypeName(obj)
+	return typeName == "timedelta"
+}
+
+// isUUID checks if a Python object is a uuid.UUID
+func (py *PureGoPython) isUUID(obj PyObject) bool {
+	typeName := py.getTypeName(obj)
+	return typeName == "UUID"
+}
+
+// isBytes checks if a Python object is a bytes object
+func (py *PureGoPython) isBytes(obj PyObject) bool {
+	typeName := py.getTypeName(obj)
+	return typeName == "bytes"
+}
+
+// isByteArray checks if a Python object is a bytearray
+func (py *PureGoPython) isByteArray(obj PyObject) bool {
+	typeName := py.getTypeName(obj)
+	return typeName == "bytearray"
+}
+
+// isMemoryView checks if a Python object is a memoryview
+func (py *PureGoPython) isMemoryView(obj PyObject) bool {
+	return py.getTypeName(obj) == "memoryview"
+}
+
+// isSet checks if a Python object is a set or frozenset
+func (py *PureGoPython) isSet(obj PyObject) bool {
+	typeName := py.getTypeName(obj)
+	return typeName == "set" || typeName == "frozenset"
+}
+
+// isNDArray checks if a Python object is a NumPy array. The type is matched by
+// name so NumPy doesn't have to be imported.
+func (py *PureGoPython) isNDArray(obj PyObject) bool {
+	return py.getTypeName(obj) == "ndarray"
+}
+
 // isList checks if a Python object is a list
 func (py *PureGoPython) isList(obj PyObject) bool {
 	typeName := py.getTypeName(obj)
@@ -203,22 +449,60 @@ func cStringToGoString(ptr *byte) string {
 	return string(result)
 }
 
-// validateFunctionRegistration checks that all critical functions are registered
-func (py *PureGoPython) validateFunctionRegistration() error {
-	if py.pyInitialize == nil {
-		return fmt.Errorf("failed to register Py_Initialize")
+// objectToString returns str(obj) as a Go string
+func (py *PureGoPython) objectToString(obj uintptr) (string, error) {
+	strObj := py.pyObjectStr(obj)
+	if strObj == 0 {
+		return "", fmt.Errorf("failed to convert object to string: %w", py.getPythonError())
 	}
-	if py.pyFinalizeEx == nil {
-		return fmt.Errorf("failed to register Py_FinalizeEx")
+	defer py.safeDecRef(strObj)
+
+	cStr := py.pyUnicodeAsUTF8(strObj)
+	if cStr == nil {
+		return "", fmt.Errorf("failed to convert string to UTF-8: %w", py.getPythonError())
 	}
-	if py.pyRunSimpleString == nil {
-		return fmt.Errorf("failed to register PyRun_SimpleString")
+	return cStringToGoString(cStr), nil
+}
+
+// objectRepr returns repr(obj) as a Go string
+func (py *PureGoPython) objectRepr(obj uintptr) (string, error) {
+	reprObj := py.pyObjectRepr(obj)
+	if reprObj == 0 {
+		return "", fmt.Errorf("failed to get repr of object: %w", py.getPythonError())
 	}
-	if py.pyImportImport == nil {
-		return fmt.Errorf("failed to register PyImport_Import")
+	defer py.safeDecRef(reprObj)
+
+	cStr := py.pyUnicodeAsUTF8(reprObj)
+	if cStr == nil {
+		return "", fmt.Errorf("failed to convert string to UTF-8: %w", py.getPythonError())
 	}
-	if py.pyObjectCallObject == nil {
-		return fmt.Errorf("failed to register PyObject_CallObject")
+	return cStringToGoString(cStr), nil
+}
+
+// cBytesToGoBytes copies size bytes starting at ptr into a new Go byte slice.
+// Unlike cStringToGoString it does not stop at NUL bytes. A negative size, as
+// returned by the C API on error, yields an empty slice.
+func cBytesToGoBytes(ptr *byte, size int) []byte {
+	if size < 0 {
+		size = 0
+	}
+	result := make([]byte, size)
+	if ptr != nil && size > 0 {
+		copy(result, unsafe.Slice(ptr, size))
+	}
+	return result
+}
+
+// validateFunctionRegistration checks that all critical functions are registered
+func (py *PureGoPython) validateFunctionRegistration() error {
+	var required []string
+	for _, name := range py.missingSymbols {
+		if _, ok := optionalSymbols[name]; !ok {
+			required = append(required, name)
+		}
+	}
+	if len(required) > 0 {
+		return fmt.Errorf("required symbols not found: %s", strings.Join(required, ", "))
 	}
 	return nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,216 @@
+package gopython
+
+import (
+	"errors"
+	"fmt"
+)
+
+// callKey identifies a function by module and attribute name
+type callKey struct {
+	module   string
+	function string
+}
+
+// PreparedCall is a Python function resolved once by Prepare. Calling it
+// skips the module import and attribute lookup that CallFunction performs on
+// every call, which matters when the same function is called in a hot loop.
+// A PreparedCall stops working when the interpreter is finalized.
+type PreparedCall struct {
+	module   string
+	function string
+	handle   *PyHandle
+}
+
+// Prepare resolves module.function and returns a PreparedCall for it.
+// Preparing the same function again reuses the cached callable.
+//
+// Example:
+//
+//	score, err := py.Prepare("model", "score")
+//	for _, row := range rows {
+//	    result, err := score.Call(row)
+//	    ...
+//	}
+func (py *PureGoPython) Prepare(module, function string) (*PreparedCall, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+
+	result, err := py.withGILReturn(func() (interface{}, error) {
+		key := callKey{module: module, function: function}
+		if h, ok := py.preparedCalls[key]; ok {
+			return h, nil
+		}
+
+		functionObj, err := py.lookupFunction(module, function)
+		if err != nil {
+			return nil, err
+		}
+		if py.preparedCalls == nil {
+			py.preparedCalls = make(map[callKey]*PyHandle)
+		}
+		h := py.newHandle(functionObj)
+		py.preparedCalls[key] = h
+		return h, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PreparedCall{module: module, function: function, handle: result.(*PyHandle)}, nil
+}
+
+// Call calls the prepared function with the given arguments and converts the
+// result to Go, like CallFunction
+func (pc *PreparedCall) Call(args ...interface{}) (interface{}, error) {
+	if pc == nil || pc.handle == nil {
+		return nil, errors.New("prepared call was not created with Prepare")
+	}
+	py := pc.handle.py
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+
+	return py.withGILReturn(func() (interface{}, error) {
+		if pc.handle.obj == 0 {
+			return nil, fmt.Errorf("prepared call to '%s.%s' is no longer valid", pc.module, pc.function)
+		}
+		resultObj, err := py.callObject(pc.handle.obj, args...)
+		if err != nil {
+			return nil, err
+		}
+		defer py.safeDecRef(resultObj)
+		return py.pythonToGo(PyObject(resultObj))
+	})
+}
+
+// Call describes one function call in a batch run by CallBatch
+type Call struct {
+	Module   string
+	Function string
+	Args     []interface{}
+}
+
+// CallResult is the outcome of one Call in a batch. Value holds the converted
+// return value and Err the error of that call alone.
+type CallResult struct {
+	Value interface{}
+	Err   error
+}
+
+// CallBatch runs calls one after another while holding the interpreter lock
+// once for the whole batch, and resolves each distinct function only once.
+// This amortizes the per-call locking and lookup cost of CallFunction when
+// scoring many records. A failing call doesn't abort the batch: its error is
+// reported in its CallResult and the next call runs. The returned error is
+// only set when the batch couldn't run at all.
+//
+// Other goroutines wait until the whole batch has finished, so split very
+// long batches to keep the interpreter responsive.
+//
+// Example:
+//
+//	calls := make([]gopython.Call, len(records))
+//	for i, record := range records {
+//	    calls[i] = gopython.Call{Module: "model", Function: "score", Args: []interface{}{record}}
+//	}
+//	results, err := py.CallBatch(calls)
+func (py *PureGoPython) CallBatch(calls []Call) ([]CallResult, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+
+	results := make([]CallResult, len(calls))
+	err := py.withGIL(func() error {
+		functions := make(map[callKey]uintptr)
+		defer func() {
+			for _, functionObj := range functions {
+				py.safeDecRef(functionObj)
+			}
+		}()
+
+		for i, call := range calls {
+			key := callKey{module: call.Module, function: call.Function}
+			functionObj, ok := functions[key]
+			if !ok {
+				var err error
+				if functionObj, err = py.lookupFunction(call.Module, call.Function); err != nil {
+					results[i].Err = err
+					continue
+				}
+				functions[key] = functionObj
+			}
+
+			resultObj, err := py.callObject(functionObj, call.Args...)
+			if err != nil {
+				results[i].Err = err
+				continue
+			}
+			results[i].Value, results[i].Err = py.pythonToGo(PyObject(resultObj))
+			py.safeDecRef(resultObj)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// cachedModule returns a new reference to the named module, importing it
+// only the first time it is requested
+func (py *PureGoPython) cachedModule(module string) (uintptr, error) {
+	if moduleObj, ok := py.modules[module]; ok {
+		py.pyIncRef(moduleObj)
+		return moduleObj, nil
+	}
+
+	moduleObj, err := py.importModule(module)
+	if err != nil {
+		return 0, err
+	}
+	if py.modules == nil {
+		py.modules = make(map[string]uintptr)
+	}
+	py.pyIncRef(moduleObj)
+	py.modules[module] = moduleObj
+	return moduleObj, nil
+}
+
+// forgetModule drops the cached module object and the prepared functions of
+// module, so the next lookup sees the module currently in sys.modules.
+// PreparedCalls into it report that they are no longer valid. Must be called
+// with the GIL held.
+func (py *PureGoPython) forgetModule(module string) {
+	if moduleObj, ok := py.modules[module]; ok {
+		py.safeDecRef(moduleObj)
+		delete(py.modules, module)
+	}
+	for key, h := range py.preparedCalls {
+		if key.module == module {
+			h.drop()
+			delete(py.preparedCalls, key)
+		}
+	}
+}
+
+// clearCallCache releases the cached modules, prepared functions and bound
+// functions. Prepared calls and bound closures created before are invalidated. Must be called with the GIL held.
+func (py *PureGoPython) clearCallCache() {
+	for name, moduleObj := range py.modules {
+		py.safeDecRef(moduleObj)
+		delete(py.modules, name)
+	}
+	for key, h := range py.preparedCalls {
+		h.drop()
+		delete(py.preparedCalls, key)
+	}
+	for name, h := range py.boundFuncs {
+		h.drop()
+		delete(py.boundFuncs, name)
+	}
+	if py.identityFunc != nil {
+		py.identityFunc.drop()
+		py.identityFunc = nil
+	}
+}
@@ -0,0 +1,829 @@
+package gopython
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ebitengine/purego"
+)
+
+// methVarargs is the METH_VARARGS calling convention flag for PyMethodDef
+const methVarargs = 0x0001
+
+// pyMethodDef mirrors the C PyMethodDef structure
+type pyMethodDef struct {
+	name  *byte
+	meth  uintptr
+	flags int32
+	doc   *byte
+}
+
+// goCallback is a Go function that can be invoked from Python
+type goCallback func(args []interface{}) (interface{}, error)
+
+// rawGoCallback is a Go function invoked from Python with unconverted
+// arguments
+type rawGoCallback func(args []*PyHandle) (*PyHandle, error)
+
+// callbackEntry ties a registered Go callback to the runtime that owns it
+type callbackEntry struct {
+	py  *PureGoPython
+	fn  goCallback
+	raw rawGoCallback // Set instead of fn for callbacks taking handles
+	def *pyMethodDef  // Kept reachable so the C side never sees freed memory
+
+	// Set for callbacks registered by the caller, whose arguments follow the
+	// caller's conversion settings. Callbacks the library creates for itself
+	// always decode with the defaults, so settings such as
+	// SetIntAsPlatformInt don't change the types they receive.
+	callerDecode bool
+}
+
+// Callbacks are dispatched through a single C trampoline because purego can
+// only create a limited number of callbacks per process. Each Python callable
+// carries the id of its registry entry as its self object. Only one libpython
+// can be loaded per process, so the trampoline decodes ids using the runtime
+// that most recently created a callable. Closing that runtime hands the role
+// to another live runtime that created callables. callbackLastOwner is never
+// cleared, so a callable outliving every such runtime can still raise.
+var (
+	callbackTrampoline     uintptr
+	callbackTrampolineOnce sync.Once
+	callbackOwner          atomic.Pointer[PureGoPython]
+	callbackLastOwner      atomic.Pointer[PureGoPython]
+	callbackRuntimes       sync.Map // *PureGoPython -> struct{}, runtimes that created callables
+	callbackRegistry       sync.Map // int64 -> *callbackEntry
+	callbackNextID         int64
+)
+
+// newGoCallable creates a Python callable that dispatches to fn without GIL
+// management, converting arguments with the default options. It returns a
+// new reference to the callable and the id of its registry entry, which must
+// be released with releaseGoCallable.
+func (py *PureGoPython) newGoCallable(name string, fn goCallback) (uintptr, int64, error) {
+	return py.newCallable(name, &callbackEntry{fn: fn})
+}
+
+// newRawGoCallable is newGoCallable for a callback taking and returning
+// handles
+func (py *PureGoPython) newRawGoCallable(name string, fn rawGoCallback) (uintptr, int64, error) {
+	return py.newCallable(name, &callbackEntry{raw: fn})
+}
+
+// newCallable creates the Python callable dispatching to entry
+func (py *PureGoPython) newCallable(name string, entry *callbackEntry) (uintptr, int64, error) {
+	if err := py.requireFeature("callbacks"); err != nil {
+		return 0, 0, err
+	}
+
+	callbackRuntimes.Store(py, struct{}{})
+	callbackOwner.Store(py)
+	callbackLastOwner.Store(py)
+	callbackTrampolineOnce.Do(func() {
+		callbackTrampoline = purego.NewCallback(dispatchGoCallback)
+	})
+
+	id := atomic.AddInt64(&callbackNextID, 1)
+	entry.py = py
+	entry.def = &pyMethodDef{
+		name:  stringToCString(name),
+		meth:  callbackTrampoline,
+		flags: methVarargs,
+	}
+
+	self := py.pyLongFromLong(id)
+	if self == 0 {
+		return 0, 0, errors.New("failed to create callback id")
+	}
+	defer py.safeDecRef(self)
+
+	callbackRegistry.Store(id, entry)
+	callable := py.pyCFunctionNewEx(entry.def, self, 0)
+	if callable == 0 {
+		callbackRegistry.Delete(id)
+		return 0, 0, fmt.Errorf("failed to create Python callable: %w", py.getPythonError())
+	}
+	return callable, id, nil
+}
+
+// releaseCallbackOwner stops py from decoding callback ids once it is closed,
+// handing the role to another runtime that created callables, if any
+func releaseCallbackOwner(py *PureGoPython) {
+	callbackRuntimes.Delete(py)
+	for callbackOwner.Load() == py {
+		var next *PureGoPython
+		callbackRuntimes.Range(func(key, _ interface{}) bool {
+			next = key.(*PureGoPython)
+			return false
+		})
+		if callbackOwner.CompareAndSwap(py, next) || next == nil {
+			return
+		}
+	}
+}
+
+// releaseGoCallable unregisters a callback. Python code that still holds the
+// callable afterwards gets a RuntimeError when calling it. The callable
+// points at its method definition, so the entry is replaced by a tombstone
+// keeping the definition alive for the rest of the process.
+func releaseGoCallable(id int64) {
+	value, ok := callbackRegistry.Load(id)
+	if !ok {
+		return
+	}
+	callbackRegistry.Store(id, &callbackEntry{def: value.(*callbackEntry).def})
+}
+
+// dispatchGoCallback is the C entry point for every Go callback. It runs on
+// the thread that called into Python, so the interpreter is already held.
+func dispatchGoCallback(self, args uintptr) uintptr {
+	owner := callbackOwner.Load()
+	if owner == nil {
+		// Every runtime that created callables is closed. The library the
+		// last one loaded is still the one running this call, so its
+		// functions can raise the error.
+		callbackLastOwner.Load().setPythonError("Go callback's runtime is closed")
+		return 0
+	}
+	id := owner.pyLongAsLong(self)
+	value, ok := callbackRegistry.Load(id)
+	if !ok {
+		owner.setPythonError("Go callback is no longer registered")
+		return 0
+	}
+	entry := value.(*callbackEntry)
+	if entry.py == nil {
+		owner.setPythonError("Go callback released")
+		return 0
+	}
+	if entry.raw != nil {
+		return entry.py.invokeRawGoCallback(entry.raw, args)
+	}
+	return entry.py.invokeGoCallback(entry.fn, entry.callerDecode, args)
+}
+
+// invokeGoCallback converts the Python arguments, with the caller's options
+// if callerDecode is set, runs fn and converts its result back, translating
+// Go errors and panics into a Python RuntimeError
+func (py *PureGoPython) invokeGoCallback(fn goCallback, callerDecode bool, args uintptr) (result uintptr) {
+	defer func() {
+		if r := recover(); r != nil {
+			py.setPythonError(fmt.Sprintf("Go callback panicked: %v", r))
+			result = 0
+		}
+	}()
+
+	opts := decodeOptions{}
+	if callerDecode {
+		opts = py.decode
+	}
+	goArgs, err := py.pythonTupleToSlice(PyObject(args), &opts, 0)
+	if err != nil && !isPartial(err) {
+		py.setPythonError(fmt.Sprintf("failed to convert callback arguments: %v", err))
+		return 0
+	}
+	// Placeholders of skipped elements are only valid during the call, like
+	// the arguments of raw callbacks
+	defer CloseAll(goArgs)
+
+	value, err := fn(goArgs)
+	if err != nil {
+		py.setPythonError(err.Error())
+		return 0
+	}
+
+	if value == nil {
+		return py.newNoneRef()
+	}
+	pyValue, err := py.goToPython(value)
+	if err != nil {
+		py.setPythonError(fmt.Sprintf("failed to convert callback result: %v", err))
+		return 0
+	}
+	return uintptr(pyValue)
+}
+
+// invokeRawGoCallback wraps the Python arguments in handles, runs fn and
+// hands the object referenced by the returned handle back to Python
+func (py *PureGoPython) invokeRawGoCallback(fn rawGoCallback, args uintptr) (result uintptr) {
+	handles := make([]*PyHandle, py.pyTupleSize(args))
+	for i := range handles {
+		// PyTuple_GetItem returns a borrowed reference
+		item := py.pyTupleGetItem(args, i)
+		py.pyIncRef(item)
+		handles[i] = py.newHandle(item)
+	}
+	defer func() {
+		for _, h := range handles {
+			h.drop()
+		}
+	}()
+
+	defer func() {
+		if r := recover(); r != nil {
+			py.setPythonError(fmt.Sprintf("Go callback panicked: %v", r))
+			result = 0
+		}
+	}()
+
+	value, err := fn(handles)
+	if err != nil {
+		py.setPythonError(err.Error())
+		return 0
+	}
+
+	if value == nil {
+		return py.newNoneRef()
+	}
+	if err := checkHandle(value); err != nil {
+		py.setPythonError(fmt.Sprintf("invalid callback result: %v", err))
+		return 0
+	}
+	result = value.obj
+	py.pyIncRef(result)
+	value.drop()
+	return result
+}
+
+// setPythonError raises a RuntimeError with the given message
+func (py *PureGoPython) setPythonError(message string) {
+	if py.pyErrSetString != nil && py.pyExcRuntimeError != 0 {
+		py.pyErrSetString(py.pyExcRuntimeError, stringToCString(message))
+	}
+}
+
+// newNoneRef returns a new reference to Py_None
+func (py *PureGoPython) newNoneRef() uintptr {
+	py.pyIncRef(py.pyNone)
+	return py.pyNone
+}
+
+// CallbackModule is the name of the Python module that callbacks registered
+// with RegisterCallback are bound into
+const CallbackModule = "gocallbacks"
+
+// RegisterCallback exposes fn to Python as gocallbacks.<name>. Arguments from
+// Python are converted to Go values and the returned value is converted back;
+// a returned error is raised in Python as a RuntimeError. Registering an
+// existing name replaces the previous callback.
+//
+// Example:
+//
+//	py.RegisterCallback("notify", func(args []interface{}) (interface{}, error) {
+//	    log.Printf("Python says: %v", args)
+//	    return true, nil
+//	})
+//	py.RunString("import gocallbacks\ngocallbacks.notify('hello')")
+//
+// A callback may call back into the library, e.g. with CallFunction. Callbacks
+// must be invoked from the thread running the Go-initiated call, not from a
+// Python background thread.
+func (py *PureGoPython) RegisterCallback(name string, fn func(args []interface{}) (interface{}, error)) error {
+	if !py.IsInitialized() {
+		return ErrNotInitialized
+	}
+	if name == "" {
+		return errors.New("callback name cannot be empty")
+	}
+	if fn == nil {
+		return errors.New("callback function cannot be nil")
+	}
+
+	return py.bindCallback(name, func() (uintptr, int64, error) {
+		return py.newCallable(name, &callbackEntry{fn: fn, callerDecode: true})
+	})
+}
+
+// RegisterCallbackRaw is RegisterCallback for callbacks that work with Python
+// objects that don't convert cleanly. Each argument is passed as a handle that
+// is valid only for the duration of the call. The object referenced by the
+// returned handle is passed back to Python and the handle is closed; a nil
+// handle returns None.
+//
+// Example:
+//
+//	py.RegisterCallbackRaw("first", func(args []*gopython.PyHandle) (*gopython.PyHandle, error) {
+//	    if len(args) == 0 {
+//	        return nil, errors.New("first expects an argument")
+//	    }
+//	    return args[0], nil
+//	})
+func (py *PureGoPython) RegisterCallbackRaw(name string, fn func(args []*PyHandle) (*PyHandle, error)) error {
+	if !py.IsInitialized() {
+		return ErrNotInitialized
+	}
+	if name == "" {
+		return errors.New("callback name cannot be empty")
+	}
+	if fn == nil {
+		return errors.New("callback function cannot be nil")
+	}
+
+	return py.bindCallback(name, func() (uintptr, int64, error) {
+		return py.newRawGoCallable(name, fn)
+	})
+}
+
+// bindCallback binds the callable returned by create into the callback
+// module under name, replacing any previous callback with that name
+func (py *PureGoPython) bindCallback(name string, create func() (uintptr, int64, error)) error {
+	return py.withGIL(func() error {
+		module := py.pyImportAddModule(stringToCString(CallbackModule))
+		if module == 0 {
+			return fmt.Errorf("failed to create module '%s': %w", CallbackModule, py.getPythonError())
+		}
+
+		callable, id, err := create()
+		if err != nil {
+			return err
+		}
+		defer py.safeDecRef(callable)
+
+		// PyObject_SetAttrString doesn't steal the reference
+		if py.pyObjectSetAttrString(module, stringToCString(name), callable) != 0 {
+			releaseGoCallable(id)
+			return fmt.Errorf("failed to bind callback '%s': %w", name, py.getPythonError())
+		}
+
+		if py.callbacks == nil {
+			py.callbacks = make(map[string]int64)
+		}
+		if previous, exists := py.callbacks[name]; exists {
+			releaseGoCallable(previous)
+		}
+		py.callbacks[name] = id
+		return nil
+	})
+}
+
+// UnregisterCallback removes a callback registered with RegisterCallback.
+// Python code still holding a reference to it gets a RuntimeError when
+// calling it.
+func (py *PureGoPython) UnregisterCallback(name string) error {
+	if !py.IsInitialized() {
+		return ErrNotInitialized
+	}
+
+	return py.withGIL(func() error {
+		id, exists := py.callbacks[name]
+		if !exists {
+			return fmt.Errorf("callback '%s' is not registered", name)
+		}
+		releaseGoCallable(id)
+		delete(py.callbacks, name)
+
+		module := py.pyImportAddModule(stringToCString(CallbackModule))
+		if module != 0 {
+			// Deleting an attribute is done by setting it to NULL
+			if py.pyObjectSetAttrString(module, stringToCString(name), 0) != 0 {
+				py.pyErrClear()
+			}
+		}
+		return nil
+	})
+}
+
+// WithProgress wraps fn as a Python callable that can be passed to a
+// long-running Python function, typically as a keyword argument via
+// CallFunctionKwargs. Python reports progress by calling it with a fraction
+// (0.0-1.0) and an optional message:
+//
+//	def train(epochs, progress=None):
+//	    for i in range(epochs):
+//	        ...
+//	        progress((i + 1) / epochs, f"epoch {i + 1}")
+//
+// The callable stays valid until the returned handle is closed. It must be
+// called from the thread running the Go-initiated call, not from a Python
+// background thread.
+func (py *PureGoPython) WithProgress(fn func(pct float64, msg string)) (*PyHandle, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+	if fn == nil {
+		return nil, errors.New("progress function cannot be nil")
+	}
+
+	progress := func(args []interface{}) (interface{}, error) {
+		if len(args) == 0 || len(args) > 2 {
+			return nil, fmt.Errorf("progress expects (pct, msg=''), got %d arguments", len(args))
+		}
+
+		var pct float64
+		switch v := args[0].(type) {
+		case float64:
+			pct = v
+		case int64:
+			pct = float64(v)
+		default:
+			return nil, fmt.Errorf("progress pct must be a number, got %T", args[0])
+		}
+
+		msg := ""
+		if len(args) == 2 && args[1] != nil {
+			s, ok := args[1].(string)
+			if !ok {
+				return nil, fmt.Errorf("progress msg must be a string, got %T", args[1])
+			}
+			msg = s
+		}
+
+		fn(pct, msg)
+		return nil, nil
+	}
+
+	result, err := py.withGILReturn(func() (interface{}, error) {
+		callable, id, err := py.newGoCallable("progress", progress)
+		if err != nil {
+			return nil, err
+		}
+		h := py.newHandle(callable)
+		h.release = func() { releaseGoCallable(id) }
+		return h, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*PyHandle), nil
+}
+
+// channelHelper wraps a Go receive callable in a Python iterator. The
+// callable returns [ok, value], with ok false once the channel is closed.
+const channelHelper = `
+class _GoChannelIterator:
+    def __init__(self, receive):
+        self._gopython_receive = receive
+
+    def __iter__(self):
+        return self
+
+    def __next__(self):
+        ok, value = self._gopython_receive()
+        if not ok:
+            raise StopIteration
+        return value
+
+def make_channel_iterator(receive):
+    return _GoChannelIterator(receive)
+`
+
+// WrapChannel returns a Python iterator that yields the values received from
+// ch, converted like arguments, and raises StopIteration once ch is closed.
+// Pass the handle to any Python function accepting an iterable to stream
+// data from Go into it:
+//
+//	records := make(chan interface{})
+//	go produce(records) // closes records when done
+//	it, _ := py.WrapChannel(records)
+//	defer it.Close()
+//	total, err := py.CallFunction("builtins", "sum", it)
+//
+// The GIL is released while waiting for the next value, but the call
+// consuming the iterator keeps the interpreter busy, so the producer must not
+// call into Python itself. The iterator stays valid until the handle is
+// closed and must be consumed from the thread running the Go-initiated call.
+func (py *PureGoPython) WrapChannel(ch <-chan interface{}) (*PyHandle, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+	if ch == nil {
+		return nil, errors.New("channel cannot be nil")
+	}
+
+	receive := func(args []interface{}) (interface{}, error) {
+		state := py.pyEvalSaveThread()
+		value, ok := <-ch
+		py.pyEvalRestoreThread(state)
+		return []interface{}{ok, value}, nil
+	}
+
+	result, err := py.withGILReturn(func() (interface{}, error) {
+		callable, id, err := py.newGoCallable("receive", receive)
+		if err != nil {
+			return nil, err
+		}
+		receiveHandle := py.newHandle(callable)
+		defer py.safeDecRef(receiveHandle.obj)
+
+		iterator, err := py.callHelper(channelHelper, "make_channel_iterator", receiveHandle)
+		if err != nil {
+			releaseGoCallable(id)
+			return nil, fmt.Errorf("failed to create channel iterator: %w", err)
+		}
+		h := py.newHandle(iterator)
+		h.release = func() { releaseGoCallable(id) }
+		return h, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*PyHandle), nil
+}
+
+// streamHelper swaps a sys stream for a file-like object that forwards writes
+// to a Go callback. The original stream is kept on the replacement so it can
+// be restored, even after repeated redirection.
+const streamHelper = `
+import sys
+
+class _GoWriter:
+    encoding = 'utf-8'
+    errors = 'strict'
+
+    def __init__(self, write, original):
+        self._gopython_write = write
+        self._gopython_original = original
+
+    def write(self, s):
+        if not isinstance(s, str):
+            raise TypeError(f"write() argument must be str, not {type(s).__name__}")
+        self._gopython_write(s)
+        return len(s)
+
+    def writelines(self, lines):
+        for line in lines:
+            self.write(line)
+
+    def flush(self):
+        pass
+
+    def isatty(self):
+        return False
+
+    def writable(self):
+        return True
+
+def _gopython_original_stream(name):
+    current = getattr(sys, name)
+    try:
+        current.flush()
+    except Exception:
+        pass
+    return getattr(current, '_gopython_original', current)
+
+def _gopython_set_stream(name, write):
+    setattr(sys, name, _GoWriter(write, _gopython_original_stream(name)))
+
+def _gopython_restore_stream(name):
+    setattr(sys, name, _gopython_original_stream(name))
+`
+
+// SetStdout redirects Python's sys.stdout to w. Each write() from Python is
+// forwarded to w as it happens, so output can be streamed into a logger
+// instead of being buffered. Passing nil restores the original stream, which
+// also happens automatically on Finalize.
+func (py *PureGoPython) SetStdout(w io.Writer) error {
+	return py.setStream("stdout", w)
+}
+
+// SetStderr redirects Python's sys.stderr to w. See SetStdout.
+func (py *PureGoPython) SetStderr(w io.Writer) error {
+	return py.setStream("stderr", w)
+}
+
+// setStream installs or removes the Go writer for the named sys stream
+func (py *PureGoPython) setStream(name string, w io.Writer) error {
+	if !py.IsInitialized() {
+		return ErrNotInitialized
+	}
+
+	return py.withGIL(func() error {
+		if w == nil {
+			return py.restoreStream(name)
+		}
+
+		forward := func(args []interface{}) (interface{}, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("write expects 1 argument, got %d", len(args))
+			}
+			s, ok := args[0].(string)
+			if !ok {
+				return nil, fmt.Errorf("write expects a string, got %T", args[0])
+			}
+			if _, err := io.WriteString(w, s); err != nil {
+				return nil, err
+			}
+			return nil, nil
+		}
+
+		callable, id, err := py.newGoCallable("write", forward)
+		if err != nil {
+			return err
+		}
+		write := py.newHandle(callable)
+		defer py.safeDecRef(write.obj)
+
+		resultObj, err := py.callHelper(streamHelper, "_gopython_set_stream", name, write)
+		if err != nil {
+			releaseGoCallable(id)
+			return fmt.Errorf("failed to redirect sys.%s: %w", name, err)
+		}
+		py.safeDecRef(resultObj)
+
+		if previous, exists := py.streams[name]; exists {
+			releaseGoCallable(previous)
+		}
+		if py.streams == nil {
+			py.streams = make(map[string]int64)
+		}
+		py.streams[name] = id
+		return nil
+	})
+}
+
+// restoreStream puts back the original sys stream replaced by setStream
+// without GIL management
+func (py *PureGoPython) restoreStream(name string) error {
+	id, exists := py.streams[name]
+	if !exists {
+		return nil
+	}
+
+	resultObj, err := py.callHelper(streamHelper, "_gopython_restore_stream", name)
+	if err != nil {
+		return fmt.Errorf("failed to restore sys.%s: %w", name, err)
+	}
+	py.safeDecRef(resultObj)
+
+	releaseGoCallable(id)
+	delete(py.streams, name)
+	return nil
+}
+
+// restoreStreams puts back every sys stream replaced by SetStdout or
+// SetStderr without GIL management
+func (py *PureGoPython) restoreStreams() {
+	for name := range py.streams {
+		if err := py.restoreStream(name); err != nil {
+			releaseGoCallable(py.streams[name])
+			delete(py.streams, name)
+		}
+	}
+}
+
+// captureHelper runs code in __main__ with sys.stdout and sys.stderr
+// redirected to buffers. contextlib's redirect_stdout/redirect_stderr are used
+// rather than swapping the streams by hand: they restore whatever stream was
+// current before, including a SetStdout writer, even when the code raises,
+// and they nest correctly. The output is stored in outputs before the
+// exception propagates so the caller still receives it.
+const captureHelper = `
+def run_captured(code, outputs):
+    import contextlib, io
+    import __main__
+    stdout, stderr = io.StringIO(), io.StringIO()
+    try:
+        with contextlib.redirect_stdout(stdout), contextlib.redirect_stderr(stderr):
+            exec(compile(code, '<string>', 'exec'), __main__.__dict__)
+    finally:
+        outputs[:] = [stdout.getvalue(), stderr.getvalue()]
+`
+
+// RunStringCaptured executes Python code in __main__ like RunString and
+// returns what it printed to sys.stdout and sys.stderr. The output written
+// before an exception is returned along with the error, and the original
+// streams are restored either way. While the code runs, writers installed
+// with SetStdout or SetStderr receive nothing; they resume afterwards.
+func (py *PureGoPython) RunStringCaptured(code string) (stdout, stderr string, err error) {
+	if !py.IsInitialized() {
+		return "", "", ErrNotInitialized
+	}
+	if _, err := codeToCString(code); err != nil {
+		return "", "", err
+	}
+
+	err = py.withGIL(func() error {
+		outputsObj, err := py.goToPython([]interface{}{})
+		if err != nil {
+			return err
+		}
+		outputs := py.newHandle(uintptr(outputsObj))
+		defer outputs.drop()
+
+		resultObj, runErr := py.callHelper(captureHelper, "run_captured", code, outputs)
+		py.safeDecRef(resultObj)
+
+		captured, err := py.internalToGo(PyObject(outputs.obj))
+		if err != nil {
+			return fmt.Errorf("failed to convert captured output: %v", err)
+		}
+		if parts, ok := captured.([]interface{}); ok && len(parts) == 2 {
+			stdout, _ = parts[0].(string)
+			stderr, _ = parts[1].(string)
+		}
+		// Report exceptions from the code itself like RunString does,
+		// without the helper call's wrapping
+		var pyErr *PyError
+		if errors.As(runErr, &pyErr) {
+			return pyErr
+		}
+		return runErr
+	})
+	return stdout, stderr, err
+}
+
+// streamingHelper runs a script like runFileHelper with sys.stdout and
+// sys.stderr redirected to Go callbacks. It is appended to streamHelper and
+// runFileHelper, whose definitions it uses.
+const streamingHelper = `
+def _gopython_run_file_streaming(path, stdout_write, stderr_write):
+    import contextlib
+    stdout = _GoWriter(stdout_write, sys.stdout)
+    stderr = _GoWriter(stderr_write, sys.stderr)
+    with contextlib.redirect_stdout(stdout), contextlib.redirect_stderr(stderr):
+        _gopython_run_file(path)
+`
+
+// lineSplitter assembles the text written to a stream into lines
+type lineSplitter struct {
+	stream  string
+	onLine  func(stream, line string)
+	partial strings.Builder
+}
+
+// write passes each line completed by text to onLine, without its newline,
+// and keeps the rest until more text arrives
+func (s *lineSplitter) write(text string) {
+	for {
+		i := strings.IndexByte(text, '\n')
+		if i < 0 {
+			s.partial.WriteString(text)
+			return
+		}
+		s.partial.WriteString(text[:i])
+		s.onLine(s.stream, s.partial.String())
+		s.partial.Reset()
+		text = text[i+1:]
+	}
+}
+
+// flush passes a final line that did not end with a newline to onLine
+func (s *lineSplitter) flush() {
+	if s.partial.Len() > 0 {
+		s.onLine(s.stream, s.partial.String())
+		s.partial.Reset()
+	}
+}
+
+// RunFileStreaming runs a Python script like RunFile, passing each line it
+// prints to onLine as soon as the line is complete, with stream set to
+// "stdout" or "stderr". Use it for a live view of long-running scripts. A
+// final line without a trailing newline is passed on when the script ends,
+// including when it fails. The original streams are restored afterwards.
+//
+// onLine is called on the calling goroutine while the script is paused, so
+// it should return quickly, e.g. by sending the line to a channel.
+func (py *PureGoPython) RunFileStreaming(filename string, onLine func(stream, line string)) error {
+	if !py.IsInitialized() {
+		return ErrNotInitialized
+	}
+	if onLine == nil {
+		return errors.New("line callback cannot be nil")
+	}
+	if err := py.requireFeature("callbacks"); err != nil {
+		return err
+	}
+
+	path, err := resolveScript(filename)
+	if err != nil {
+		return err
+	}
+
+	return py.withGIL(func() error {
+		var writers []interface{}
+		for _, stream := range []string{"stdout", "stderr"} {
+			splitter := &lineSplitter{stream: stream, onLine: onLine}
+			defer splitter.flush()
+
+			callable, id, err := py.newGoCallable("write", func(args []interface{}) (interface{}, error) {
+				if len(args) != 1 {
+					return nil, fmt.Errorf("write expects 1 argument, got %d", len(args))
+				}
+				text, ok := args[0].(string)
+				if !ok {
+					return nil, fmt.Errorf("write expects a string, got %T", args[0])
+				}
+				splitter.write(text)
+				return nil, nil
+			})
+			if err != nil {
+				return err
+			}
+			defer releaseGoCallable(id)
+			write := py.newHandle(callable)
+			defer write.drop()
+			writers = append(writers, write)
+		}
+
+		resultObj, err := py.callHelper(streamHelper+runFileHelper+streamingHelper, "_gopython_run_file_streaming", path, writers[0], writers[1])
+		if err != nil {
+			return fmt.Errorf("failed to run file %s: %w", filename, err)
+		}
+		py.safeDecRef(resultObj)
+		return nil
+	})
+}
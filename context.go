@@ -0,0 +1,230 @@
+package gopython
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"runtime"
+	"sync"
+)
+
+// CallFunctionContext calls a Python function like CallFunction, aborting it
+// when ctx is cancelled or its deadline passes. KeyboardInterrupt is raised in
+// the thread running the call, so the Python code stops at its next bytecode
+// boundary and the interpreter is released for other callers. The returned
+// error then wraps ctx.Err(). Code blocked inside C, such as a long
+// time.sleep or a socket read, is interrupted once it returns to Python.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+//	defer cancel()
+//	result, err := py.CallFunctionContext(ctx, "scripts", "run_report", params)
+//	if errors.Is(err, context.DeadlineExceeded) {
+//	    ...
+//	}
+func (py *PureGoPython) CallFunctionContext(ctx context.Context, module, function string, args ...interface{}) (interface{}, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if ctx.Done() == nil {
+		return py.CallFunction(module, function, args...)
+	}
+	for _, feature := range []string{"cancellation", "true GIL management"} {
+		if err := py.requireFeature(feature); err != nil {
+			return nil, err
+		}
+	}
+
+	return py.withGILReturn(func() (interface{}, error) {
+		functionObj, err := py.lookupFunction(module, function)
+		if err != nil {
+			return nil, err
+		}
+		defer py.safeDecRef(functionObj)
+
+		stop, err := py.interruptOnDone(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resultObj, err := py.callObject(functionObj, args...)
+		interrupted := stop()
+		if err != nil {
+			if interrupted {
+				return nil, fmt.Errorf("call to '%s.%s' cancelled: %w", module, function, ctx.Err())
+			}
+			return nil, err
+		}
+		defer py.safeDecRef(resultObj)
+
+		return py.pythonToGo(PyObject(resultObj))
+	})
+}
+
+// interruptOnDone raises KeyboardInterrupt in the calling thread state once
+// ctx is done. The returned stop function ends the watch, drops an interrupt
+// that has not been raised yet and reports whether one was sent. Must be
+// called with the GIL held, and stop from the same call.
+func (py *PureGoPython) interruptOnDone(ctx context.Context) (func() bool, error) {
+	ident, err := py.currentThreadIdent()
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	done, fired := false, false
+	finished := make(chan struct{})
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-finished:
+			return
+		}
+
+		// The call may be waiting in C with the GIL released (time.sleep
+		// does so even with the default mutex), so the interrupt is sent
+		// from a thread state of its own holding the GIL
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		mu.Lock()
+		stopped := done
+		mu.Unlock()
+		if stopped {
+			return
+		}
+		state := py.pyGILStateEnsure()
+		defer py.pyGILStateRelease(state)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if !done {
+			py.pyThreadStateSetAsyncExc(ident, py.pyExcKeyboardInterrupt)
+			fired = true
+		}
+	}()
+
+	return func() bool {
+		close(finished)
+		mu.Lock()
+		done = true
+		interrupted := fired
+		mu.Unlock()
+		if interrupted {
+			// Clear the interrupt in case the call returned before raising it
+			py.pyThreadStateSetAsyncExc(ident, 0)
+		}
+		return interrupted
+	}, nil
+}
+
+const threadIdentHelper = `
+import sys
+
+def current_thread_ident():
+    frame = sys._getframe()
+    for ident, top in sys._current_frames().items():
+        if top is frame:
+            return ident
+    return 0
+`
+
+// currentThreadIdent returns the thread id of the thread state running Python
+// code for the current call. With the default mutex this is the thread that
+// initialized the interpreter rather than the calling OS thread, so it is
+// looked up from Python instead of with PyThread_get_thread_ident.
+func (py *PureGoPython) currentThreadIdent() (uint64, error) {
+	resultObj, err := py.callHelper(threadIdentHelper, "current_thread_ident")
+	if err != nil {
+		return 0, err
+	}
+	defer py.safeDecRef(resultObj)
+
+	result, err := py.internalToGo(PyObject(resultObj))
+	if err != nil {
+		return 0, err
+	}
+	if ident, ok := toThreadIdent(result); ok {
+		return ident, nil
+	}
+	return 0, fmt.Errorf("failed to identify the running thread state")
+}
+
+// toThreadIdent converts a thread id returned by Python, which may not fit
+// in an int64, to the unsigned long PyThreadState_SetAsyncExc expects
+func toThreadIdent(value interface{}) (uint64, bool) {
+	switch ident := value.(type) {
+	case int64:
+		if ident > 0 {
+			return uint64(ident), true
+		}
+	case *big.Int:
+		if ident.IsUint64() {
+			return ident.Uint64(), true
+		}
+	}
+	return 0, false
+}
+
+const runningThreadsHelper = `
+import sys, threading
+
+def running_thread_idents():
+    me = threading.get_ident()
+    return [ident for ident in sys._current_frames() if ident != me]
+`
+
+// InterruptAll raises KeyboardInterrupt in every thread currently running
+// Python code and returns how many were interrupted. It aborts the in-flight
+// calls of all goroutines at once, along with threads started by Python code,
+// for example when a server is shutting down: stop accepting requests, call
+// InterruptAll, then wait for the calls to return. Interrupted calls fail
+// with a *PyError of type KeyboardInterrupt unless the Python code catches it.
+//
+// As with CallFunctionContext, Python only notices the exception at its next
+// bytecode boundary, so code blocked inside C (a long time.sleep, a socket
+// read, a C extension computing) is interrupted once it returns to Python. A
+// call that finishes just as InterruptAll runs may leave the interrupt
+// pending; it is then raised by the next Python code its thread state runs.
+//
+// Py_AddPendingCall is not used because CPython runs pending calls only on
+// the thread that initialized the interpreter, while calls run on the OS
+// thread of whichever goroutine made them.
+func (py *PureGoPython) InterruptAll() (int, error) {
+	if !py.IsInitialized() {
+		return 0, ErrNotInitialized
+	}
+	for _, feature := range []string{"cancellation", "true GIL management"} {
+		if err := py.requireFeature(feature); err != nil {
+			return 0, err
+		}
+	}
+
+	// The running calls hold the interpreter, so the GIL is taken with a
+	// thread state of its own rather than through withGIL
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	state := py.pyGILStateEnsure()
+	defer py.pyGILStateRelease(state)
+
+	resultObj, err := py.callHelper(runningThreadsHelper, "running_thread_idents")
+	if err != nil {
+		return 0, fmt.Errorf("failed to list running threads: %w", err)
+	}
+	defer py.safeDecRef(resultObj)
+
+	result, err := py.internalToGo(PyObject(resultObj))
+	if err != nil {
+		return 0, err
+	}
+	interrupted := 0
+	for _, value := range result.([]interface{}) {
+		if ident, ok := toThreadIdent(value); ok {
+			interrupted += py.pyThreadStateSetAsyncExc(ident, py.pyExcKeyboardInterrupt)
+		}
+	}
+	return interrupted, nil
+}
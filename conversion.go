@@ -1,38 +1,169 @@
 package gopython
 
 import (
+	"encoding/binary"
+	"errors"
 	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"runtime"
+	"strconv"
+	"time"
+	"unicode/utf8"
 	"unsafe"
 )
 
+// DefaultMaxConversionDepth is the nesting depth allowed during conversion
+// when SetMaxConversionDepth has not been called
+const DefaultMaxConversionDepth = 100
+
+// ErrMaxDepthExceeded is returned when a value is nested deeper than the
+// configured maximum conversion depth
+var ErrMaxDepthExceeded = errors.New("max depth exceeded")
+
+// SetMaxConversionDepth sets the maximum nesting depth for conversions between
+// Go and Python containers. Values nested deeper than n fail with a
+// "max depth exceeded" error instead of recursing further. A value of n <= 0
+// restores DefaultMaxConversionDepth.
+func (py *PureGoPython) SetMaxConversionDepth(n int) error {
+	return py.updateSettings(func() {
+		py.maxConversionDepth = n
+	})
+}
+
+// conversionDepthLimit returns the effective maximum conversion depth
+func (py *PureGoPython) conversionDepthLimit() int {
+	if py.maxConversionDepth <= 0 {
+		return DefaultMaxConversionDepth
+	}
+	return py.maxConversionDepth
+}
+
+// checkConversionDepth returns an error if depth exceeds the configured limit
+func (py *PureGoPython) checkConversionDepth(depth int) error {
+	if limit := py.conversionDepthLimit(); depth > limit {
+		return fmt.Errorf("%w: value is nested deeper than %d levels", ErrMaxDepthExceeded, limit)
+	}
+	return nil
+}
+
+// SetNumericFallback enables generic numeric extraction for objects that are
+// not plain ints or floats but expose __array__ or the buffer protocol, such
+// as NumPy scalars, 0-d NumPy arrays and pandas scalars. Such objects are
+// converted through __index__ to int64 when they are integral and through
+// __float__ to float64 otherwise. Disabled by default.
+func (py *PureGoPython) SetNumericFallback(enabled bool) error {
+	return py.updateSettings(func() {
+		py.decode.numericFallback = enabled
+	})
+}
+
+// SetIntAsPlatformInt makes Python ints convert to Go int instead of int64,
+// saving a cast where results feed APIs taking int. Values that don't fit in
+// int (beyond 32 bits on 32-bit platforms, beyond 64 bits elsewhere) fail
+// the conversion rather than being truncated. Disabled by default, so the
+// result type doesn't depend on the platform.
+func (py *PureGoPython) SetIntAsPlatformInt(enabled bool) error {
+	return py.updateSettings(func() {
+		py.decode.intAsPlatformInt = enabled
+	})
+}
+
+// SetFloatRepr makes ResultAsString format float results with Python's repr,
+// so they read exactly as Python would print them: 2.0 rather than 2,
+// 1000000000000000.0 rather than 1e+15 and inf rather than +Inf. Disabled by
+// default, in which case floats are formatted with strconv's shortest
+// representation.
+func (py *PureGoPython) SetFloatRepr(enabled bool) error {
+	return py.updateSettings(func() {
+		py.floatRepr = enabled
+	})
+}
+
+// SetReprFallback makes objects of types without a Go conversion, such as
+// instances of custom classes, convert to an UnknownValue holding their type
+// name and repr() instead of failing the whole conversion. A result mixing
+// known values with one exotic object then still converts, which helps when
+// logging or debugging. ConvertDeepOrHandle also returns UnknownValue
+// instead of handles for such objects while it is enabled. Disabled by
+// default.
+func (py *PureGoPython) SetReprFallback(enabled bool) error {
+	return py.updateSettings(func() {
+		py.decode.reprFallback = enabled
+	})
+}
+
+// SetSkipUnconvertible makes list, tuple and set items and dict values that
+// have no Go conversion, such as instances of custom classes, turn into a
+// placeholder instead of failing the whole conversion, so one odd element
+// doesn't lose the rest of a result. The placeholder is nil, or a *PyHandle
+// to the element with AsHandle, which the caller closes with CloseAll. With
+// CollectErrors the partial result comes with a ConversionErrors error
+// listing the skipped elements; GetGlobal, GetModuleAttr, Vars and the
+// iteration methods return it the same way. Placeholders in the arguments of
+// a callback are only valid during the call. Dict keys and values nested
+// deeper than the maximum conversion depth still fail the conversion, and
+// the placeholders already created are released. Disabled by default.
+func (py *PureGoPython) SetSkipUnconvertible(opts SkipUnconvertible) error {
+	return py.updateSettings(func() {
+		py.decode.skipUnconvertible = opts
+	})
+}
+
+// SetPreserveDictOrder makes Python dicts convert to OrderedDictValue, keeping
+// the insertion order Python guarantees, instead of map[string]interface{}.
+// Useful when re-emitting JSON or YAML with the same key order as Python.
+// Disabled by default.
+func (py *PureGoPython) SetPreserveDictOrder(enabled bool) error {
+	return py.updateSettings(func() {
+		py.decode.preserveDictOrder = enabled
+	})
+}
+
 // goToPython converts Go values to Python objects
 func (py *PureGoPython) goToPython(value interface{}) (PyObject, error) {
+	return py.goToPythonDepth(value, 0)
+}
+
+// goToPythonDepth converts Go values to Python objects, tracking container nesting depth
+func (py *PureGoPython) goToPythonDepth(value interface{}, depth int) (PyObject, error) {
 	if value == nil {
-		return 0, nil // Python None
+		return PyObject(py.newNoneRef()), nil
+	}
+
+	if err := py.checkConversionDepth(depth); err != nil {
+		return 0, err
 	}
 
 	switch v := value.(type) {
 	case string:
-		cStr := stringToCString(v)
-		pyStr := py.pyUnicodeFromString(cStr)
-		if pyStr == 0 {
-			return 0, fmt.Errorf("failed to create Python string")
+		if !utf8.ValidString(v) {
+			return py.rawStringToPython(v)
 		}
-		return PyObject(pyStr), nil
+		return py.stringToPython(v)
 
 	case int:
-		pyInt := py.pyLongFromLong(int64(v))
-		if pyInt == 0 {
-			return 0, fmt.Errorf("failed to create Python int")
-		}
-		return PyObject(pyInt), nil
-
+		return py.intToPython(int64(v))
 	case int64:
-		pyInt := py.pyLongFromLong(v)
-		if pyInt == 0 {
-			return 0, fmt.Errorf("failed to create Python int")
-		}
-		return PyObject(pyInt), nil
+		return py.intToPython(v)
+	case int8:
+		return py.intToPython(int64(v))
+	case int16:
+		return py.intToPython(int64(v))
+	case int32:
+		return py.intToPython(int64(v))
+
+	case uint:
+		return py.uintToPython(uint64(v))
+	case uint8:
+		return py.uintToPython(uint64(v))
+	case uint16:
+		return py.uintToPython(uint64(v))
+	case uint32:
+		return py.uintToPython(uint64(v))
+	case uint64:
+		return py.uintToPython(v)
 
 	case float64:
 		pyFloat := py.pyFloatFromDouble(v)
@@ -41,6 +172,19 @@ func (py *PureGoPython) goToPython(value interface{}) (PyObject, error) {
 		}
 		return PyObject(pyFloat), nil
 
+	case float32:
+		pyFloat := py.pyFloatFromDouble(float64(v))
+		if pyFloat == 0 {
+			return 0, fmt.Errorf("failed to create Python float")
+		}
+		return PyObject(pyFloat), nil
+
+	case complex128:
+		return py.complexToPython(v)
+
+	case complex64:
+		return py.complexToPython(complex128(v))
+
 	case bool:
 		var pyBool uintptr
 		if v {
@@ -53,28 +197,143 @@ func (py *PureGoPython) goToPython(value interface{}) (PyObject, error) {
 		}
 		return PyObject(pyBool), nil
 
+	case *big.Int:
+		if v == nil {
+			return 0, fmt.Errorf("nil *big.Int")
+		}
+		pyInt := py.pyLongFromString(stringToCString(v.String()), nil, 10)
+		if pyInt == 0 {
+			return 0, fmt.Errorf("failed to create Python int: %w", py.getPythonError())
+		}
+		return PyObject(pyInt), nil
+
+	case time.Duration:
+		return py.durationToPython(v)
+
+	case time.Time:
+		return py.timeToPython(v)
+
+	case UUID:
+		return py.uuidToPython(v)
+
+	case []byte:
+		return py.bytesToPython(v)
+
+	case ByteArray:
+		return py.byteArrayToPython(v)
+
+	case *PyHandle:
+		if err := checkHandle(v); err != nil {
+			return 0, err
+		}
+		// The caller receives a new reference; the handle keeps its own
+		py.pyIncRef(v.obj)
+		return PyObject(v.obj), nil
+
 	case []interface{}:
-		return py.sliceToPythonList(v)
+		return py.sliceToPythonList(v, depth)
 
 	case map[string]interface{}:
-		return py.mapToPythonDict(v)
+		return py.mapToPythonDict(v, depth)
+
+	case map[interface{}]interface{}:
+		return py.anyMapToPythonDict(v, depth)
+
+	case SetValue:
+		return py.sliceToPythonSet(v, depth)
+
+	case FrozenSetValue:
+		return py.sliceToPythonFrozenSet(v, depth)
+
+	case OrderedDictValue:
+		return py.orderedToPythonDict(v, depth)
 
 	default:
-		return 0, fmt.Errorf("unsupported Go type: %T", value)
+		return py.containerToPython(value, depth)
 	}
 }
 
+// containerToPython converts typed slices, arrays and maps such as []int,
+// [3]string or map[string][]float64 through the []interface{} and map cases:
+// slices and arrays become lists, maps with string keys become dicts like
+// map[string]interface{} and other maps like map[interface{}]interface{}.
+// Their elements are converted recursively like any other value.
+func (py *PureGoPython) containerToPython(value interface{}, depth int) (PyObject, error) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = v.Index(i).Interface()
+		}
+		return py.sliceToPythonList(items, depth)
+
+	case reflect.Map:
+		iter := v.MapRange()
+		if v.Type().Key().Kind() == reflect.String {
+			m := make(map[string]interface{}, v.Len())
+			for iter.Next() {
+				m[iter.Key().String()] = iter.Value().Interface()
+			}
+			return py.mapToPythonDict(m, depth)
+		}
+		m := make(map[interface{}]interface{}, v.Len())
+		for iter.Next() {
+			m[iter.Key().Interface()] = iter.Value().Interface()
+		}
+		return py.anyMapToPythonDict(m, depth)
+	}
+	return 0, fmt.Errorf("unsupported Go type: %T", value)
+}
+
+// intToPython converts a Go integer widened to int64 to a Python int
+func (py *PureGoPython) intToPython(v int64) (PyObject, error) {
+	pyInt := py.pyLongFromLong(v)
+	if pyInt == 0 {
+		return 0, fmt.Errorf("failed to create Python int")
+	}
+	return PyObject(pyInt), nil
+}
+
+// uintToPython converts an unsigned Go integer to a Python int. Values above
+// math.MaxInt64 need PyLong_FromUnsignedLongLong to avoid wrapping negative.
+func (py *PureGoPython) uintToPython(v uint64) (PyObject, error) {
+	if err := py.requireFeature("unsigned integers"); err != nil {
+		return 0, err
+	}
+	pyInt := py.pyLongFromUnsignedLongLong(v)
+	if pyInt == 0 {
+		return 0, fmt.Errorf("failed to create Python int")
+	}
+	return PyObject(pyInt), nil
+}
+
+// complexToPython converts a Go complex number to a Python complex
+func (py *PureGoPython) complexToPython(v complex128) (PyObject, error) {
+	if err := py.requireFeature("complex numbers"); err != nil {
+		return 0, err
+	}
+	pyComplex := py.pyComplexFromDoubles(real(v), imag(v))
+	if pyComplex == 0 {
+		return 0, fmt.Errorf("failed to create Python complex")
+	}
+	return PyObject(pyComplex), nil
+}
+
 // sliceToPythonList converts a Go slice to a Python list
-func (py *PureGoPython) sliceToPythonList(slice []interface{}) (PyObject, error) {
+func (py *PureGoPython) sliceToPythonList(slice []interface{}, depth int) (PyObject, error) {
 	pyList := py.pyListNew(len(slice))
 	if pyList == 0 {
 		return 0, fmt.Errorf("failed to create Python list")
 	}
 
 	for i, item := range slice {
-		pyItem, err := py.goToPython(item)
+		pyItem, err := py.goToPythonDepth(item, depth+1)
 		if err != nil {
 			py.safeDecRef(pyList)
+			if errors.Is(err, ErrMaxDepthExceeded) {
+				return 0, err
+			}
 			return 0, fmt.Errorf("failed to convert slice item %d: %v", i, err)
 		}
 
@@ -88,17 +347,346 @@ func (py *PureGoPython) sliceToPythonList(slice []interface{}) (PyObject, error)
 	return PyObject(pyList), nil
 }
 
+// sliceToPythonSet converts the elements of a SetValue to a Python set
+func (py *PureGoPython) sliceToPythonSet(items SetValue, depth int) (PyObject, error) {
+	if err := py.requireFeature("sets"); err != nil {
+		return 0, err
+	}
+	pySet := py.pySetNew(0)
+	if pySet == 0 {
+		return 0, fmt.Errorf("failed to create Python set")
+	}
+
+	for i, item := range items {
+		pyItem, err := py.goToPythonDepth(item, depth+1)
+		if err != nil {
+			py.safeDecRef(pySet)
+			if errors.Is(err, ErrMaxDepthExceeded) {
+				return 0, err
+			}
+			return 0, fmt.Errorf("failed to convert set item %d: %v", i, err)
+		}
+
+		// PySet_Add doesn't steal the reference
+		status := py.pySetAdd(pySet, uintptr(pyItem))
+		py.safeDecRef(uintptr(pyItem))
+		if status != 0 {
+			py.safeDecRef(pySet)
+			return 0, fmt.Errorf("failed to add set item %d: %w", i, py.getPythonError())
+		}
+	}
+
+	return PyObject(pySet), nil
+}
+
+// sliceToPythonFrozenSet converts the elements of a FrozenSetValue to a
+// Python frozenset
+func (py *PureGoPython) sliceToPythonFrozenSet(items FrozenSetValue, depth int) (PyObject, error) {
+	pySet, err := py.sliceToPythonSet(SetValue(items), depth)
+	if err != nil {
+		return 0, err
+	}
+	defer py.safeDecRef(uintptr(pySet))
+
+	pyFrozenSet := py.pyFrozenSetNew(uintptr(pySet))
+	if pyFrozenSet == 0 {
+		return 0, fmt.Errorf("failed to create Python frozenset: %w", py.getPythonError())
+	}
+	return PyObject(pyFrozenSet), nil
+}
+
+// durationToPython converts a Go duration to a Python datetime.timedelta with
+// microsecond precision
+func (py *PureGoPython) durationToPython(d time.Duration) (PyObject, error) {
+	datetimeModule, err := py.importModule("datetime")
+	if err != nil {
+		return 0, err
+	}
+	defer py.safeDecRef(datetimeModule)
+
+	timedeltaClass := py.getAttrString(datetimeModule, "timedelta")
+	if timedeltaClass == 0 {
+		return 0, fmt.Errorf("failed to get datetime.timedelta")
+	}
+	defer py.safeDecRef(timedeltaClass)
+
+	// timedelta(days, seconds, microseconds) normalizes the values itself
+	args, err := py.buildArgumentTuple(0, 0, int64(d/time.Microsecond))
+	if err != nil {
+		return 0, err
+	}
+	defer py.safeDecRef(uintptr(args))
+
+	pyDelta := py.pyObjectCallObject(timedeltaClass, uintptr(args))
+	if pyDelta == 0 {
+		return 0, fmt.Errorf("failed to create Python timedelta: %w", py.getPythonError())
+	}
+	return PyObject(pyDelta), nil
+}
+
+// timeToPython converts a Go time to a timezone-aware Python
+// datetime.datetime. The tzinfo is a fixed-offset datetime.timezone carrying
+// the zone's offset and abbreviation at that instant, or timezone.utc for UTC.
+// A time on January 1 of year 0, which datetime can't represent and which
+// pythonTimeOfDayToGo produces, converts back to a datetime.time.
+func (py *PureGoPython) timeToPython(t time.Time) (PyObject, error) {
+	datetimeModule, err := py.importModule("datetime")
+	if err != nil {
+		return 0, err
+	}
+	defer py.safeDecRef(datetimeModule)
+
+	tz, err := py.timezoneToPython(datetimeModule, t)
+	if err != nil {
+		return 0, err
+	}
+	tzHandle := py.newHandle(tz)
+	defer tzHandle.drop()
+
+	if isTimeOfDay(t) {
+		return py.timeOfDayToPython(datetimeModule, t, tzHandle)
+	}
+
+	datetimeClass := py.getAttrString(datetimeModule, "datetime")
+	if datetimeClass == 0 {
+		return 0, fmt.Errorf("failed to get datetime.datetime")
+	}
+	defer py.safeDecRef(datetimeClass)
+
+	// datetime(year, month, day, hour, minute, second, microsecond, tzinfo)
+	args, err := py.buildArgumentTuple(t.Year(), int(t.Month()), t.Day(),
+		t.Hour(), t.Minute(), t.Second(), t.Nanosecond()/1000, tzHandle)
+	if err != nil {
+		return 0, err
+	}
+	defer py.safeDecRef(uintptr(args))
+
+	pyDatetime := py.pyObjectCallObject(datetimeClass, uintptr(args))
+	if pyDatetime == 0 {
+		return 0, fmt.Errorf("failed to create Python datetime: %w", py.getPythonError())
+	}
+	return PyObject(pyDatetime), nil
+}
+
+// isTimeOfDay reports whether t is on January 1 of year 0, the date
+// pythonTimeOfDayToGo gives a datetime.time
+func isTimeOfDay(t time.Time) bool {
+	return t.Year() == 0 && t.Month() == time.January && t.Day() == 1
+}
+
+// timeOfDayToPython converts the clock time of t to a Python datetime.time
+// with the given tzinfo
+func (py *PureGoPython) timeOfDayToPython(datetimeModule uintptr, t time.Time, tz *PyHandle) (PyObject, error) {
+	timeClass := py.getAttrString(datetimeModule, "time")
+	if timeClass == 0 {
+		return 0, fmt.Errorf("failed to get datetime.time")
+	}
+	defer py.safeDecRef(timeClass)
+
+	// time(hour, minute, second, microsecond, tzinfo)
+	args, err := py.buildArgumentTuple(t.Hour(), t.Minute(), t.Second(), t.Nanosecond()/1000, tz)
+	if err != nil {
+		return 0, err
+	}
+	defer py.safeDecRef(uintptr(args))
+
+	pyTime := py.pyObjectCallObject(timeClass, uintptr(args))
+	if pyTime == 0 {
+		return 0, fmt.Errorf("failed to create Python time: %w", py.getPythonError())
+	}
+	return PyObject(pyTime), nil
+}
+
+// timezoneToPython returns a new reference to the datetime.timezone matching
+// the location of t
+func (py *PureGoPython) timezoneToPython(datetimeModule uintptr, t time.Time) (uintptr, error) {
+	timezoneClass := py.getAttrString(datetimeModule, "timezone")
+	if timezoneClass == 0 {
+		return 0, fmt.Errorf("failed to get datetime.timezone")
+	}
+	defer py.safeDecRef(timezoneClass)
+
+	name, offset := t.Zone()
+	if t.Location() == time.UTC {
+		utc := py.getAttrString(timezoneClass, "utc")
+		if utc == 0 {
+			return 0, fmt.Errorf("failed to get datetime.timezone.utc")
+		}
+		return utc, nil
+	}
+
+	tzArgs := []interface{}{time.Duration(offset) * time.Second}
+	if name != "" {
+		tzArgs = append(tzArgs, name)
+	}
+	args, err := py.buildArgumentTuple(tzArgs...)
+	if err != nil {
+		return 0, err
+	}
+	defer py.safeDecRef(uintptr(args))
+
+	tz := py.pyObjectCallObject(timezoneClass, uintptr(args))
+	if tz == 0 {
+		return 0, fmt.Errorf("failed to create Python timezone: %w", py.getPythonError())
+	}
+	return tz, nil
+}
+
+// uuidToPython converts a Go UUID string to a Python uuid.UUID
+func (py *PureGoPython) uuidToPython(u UUID) (PyObject, error) {
+	pyUUID, err := py.callFunctionObject("uuid", "UUID", string(u))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create Python UUID: %w", err)
+	}
+	return PyObject(pyUUID), nil
+}
+
+// bytesToPython converts a Go byte slice to a Python bytes object.
+// The length is passed explicitly so embedded zero bytes are preserved.
+func (py *PureGoPython) bytesToPython(b []byte) (PyObject, error) {
+	var ptr *byte
+	if len(b) > 0 {
+		ptr = &b[0]
+	}
+	pyBytes := py.pyBytesFromStringAndSize(ptr, len(b))
+	if pyBytes == 0 {
+		return 0, fmt.Errorf("failed to create Python bytes")
+	}
+	return PyObject(pyBytes), nil
+}
+
+// anyMapToPythonDict converts a Go map with arbitrary keys to a Python
+// dictionary, converting the keys as well as the values
+func (py *PureGoPython) anyMapToPythonDict(m map[interface{}]interface{}, depth int) (PyObject, error) {
+	pyDict := py.pyDictNew()
+	if pyDict == 0 {
+		return 0, fmt.Errorf("failed to create Python dict")
+	}
+
+	for key, value := range m {
+		pyKey, err := py.goToPythonDepth(key, depth+1)
+		if err != nil {
+			py.safeDecRef(pyDict)
+			if errors.Is(err, ErrMaxDepthExceeded) {
+				return 0, err
+			}
+			return 0, fmt.Errorf("failed to convert dict key %v: %v", key, err)
+		}
+		pyValue, err := py.goToPythonDepth(value, depth+1)
+		if err != nil {
+			py.safeDecRef(uintptr(pyKey))
+			py.safeDecRef(pyDict)
+			if errors.Is(err, ErrMaxDepthExceeded) {
+				return 0, err
+			}
+			return 0, fmt.Errorf("failed to convert dict value for key %v: %v", key, err)
+		}
+
+		// PyDict_SetItem doesn't steal the references
+		status := py.pyDictSetItem(pyDict, uintptr(pyKey), uintptr(pyValue))
+		py.safeDecRef(uintptr(pyKey))
+		py.safeDecRef(uintptr(pyValue))
+		if status != 0 {
+			py.safeDecRef(pyDict)
+			return 0, fmt.Errorf("failed to set dict item for key %v: %w", key, py.getPythonError())
+		}
+	}
+
+	return PyObject(pyDict), nil
+}
+
+// orderedToPythonDict converts an OrderedDictValue to a Python dictionary,
+// inserting the keys in order
+func (py *PureGoPython) orderedToPythonDict(entries OrderedDictValue, depth int) (PyObject, error) {
+	pyDict := py.pyDictNew()
+	if pyDict == 0 {
+		return 0, fmt.Errorf("failed to create Python dict")
+	}
+
+	for _, entry := range entries {
+		pyValue, err := py.goToPythonDepth(entry.Value, depth+1)
+		if err != nil {
+			py.safeDecRef(pyDict)
+			if errors.Is(err, ErrMaxDepthExceeded) {
+				return 0, err
+			}
+			return 0, fmt.Errorf("failed to convert dict value for key '%s': %v", entry.Key, err)
+		}
+
+		// PyDict_SetItemString doesn't steal the reference
+		status := py.pyDictSetItemString(pyDict, stringToCString(entry.Key), uintptr(pyValue))
+		py.safeDecRef(uintptr(pyValue))
+		if status != 0 {
+			py.safeDecRef(pyDict)
+			return 0, fmt.Errorf("failed to set dict item for key '%s'", entry.Key)
+		}
+	}
+
+	return PyObject(pyDict), nil
+}
+
+// byteArrayToPython converts a Go byte slice to a Python bytearray
+func (py *PureGoPython) byteArrayToPython(b ByteArray) (PyObject, error) {
+	if err := py.requireFeature("bytearray"); err != nil {
+		return 0, err
+	}
+	var ptr *byte
+	if len(b) > 0 {
+		ptr = &b[0]
+	}
+	pyByteArray := py.pyByteArrayFromStringAndSize(ptr, len(b))
+	if pyByteArray == 0 {
+		return 0, fmt.Errorf("failed to create Python bytearray")
+	}
+	return PyObject(pyByteArray), nil
+}
+
+// stringToPython converts a valid UTF-8 Go string to a Python str. The
+// string's bytes are decoded in place with PyUnicode_FromStringAndSize, so
+// large strings are copied once, by Python, instead of first into a
+// NUL-terminated buffer, and embedded NUL characters are kept.
+func (py *PureGoPython) stringToPython(s string) (PyObject, error) {
+	var pinner runtime.Pinner
+	defer pinner.Unpin()
+	data := unsafe.StringData(s)
+	if data != nil {
+		pinner.Pin(data)
+	}
+
+	pyStr := py.pyUnicodeFromStringAndSize(data, len(s))
+	runtime.KeepAlive(s)
+	if pyStr == 0 {
+		return 0, fmt.Errorf("failed to create Python string: %w", py.getPythonError())
+	}
+	return PyObject(pyStr), nil
+}
+
+// rawStringToPython converts a Go string that is not valid UTF-8, such as a
+// non-UTF-8 filename, to a Python str. Invalid bytes become lone surrogates
+// (the surrogateescape error handler), matching how Python decodes os paths.
+func (py *PureGoPython) rawStringToPython(s string) (PyObject, error) {
+	b := []byte(s)
+	pyStr := py.pyUnicodeDecodeUTF8(&b[0], len(b), stringToCString("surrogateescape"))
+	if pyStr == 0 {
+		return 0, fmt.Errorf("failed to create Python string: %w", py.getPythonError())
+	}
+	return PyObject(pyStr), nil
+}
+
 // mapToPythonDict converts a Go map to a Python dictionary
-func (py *PureGoPython) mapToPythonDict(m map[string]interface{}) (PyObject, error) {
+func (py *PureGoPython) mapToPythonDict(m map[string]interface{}, depth int) (PyObject, error) {
 	pyDict := py.pyDictNew()
 	if pyDict == 0 {
 		return 0, fmt.Errorf("failed to create Python dict")
 	}
 
 	for key, value := range m {
-		pyValue, err := py.goToPython(value)
+		pyValue, err := py.goToPythonDepth(value, depth+1)
 		if err != nil {
 			py.safeDecRef(pyDict)
+			if errors.Is(err, ErrMaxDepthExceeded) {
+				return 0, err
+			}
 			return 0, fmt.Errorf("failed to convert dict value for key '%s': %v", key, err)
 		}
 
@@ -118,27 +706,47 @@ func (py *PureGoPython) mapToPythonDict(m map[string]interface{}) (PyObject, err
 
 // pythonToGo converts Python objects to Go values
 func (py *PureGoPython) pythonToGo(obj PyObject) (interface{}, error) {
+	opts := py.decode
+	return py.pythonToGoDepth(obj, &opts, 0)
+}
+
+// internalToGo converts the result of a library helper to Go with the default
+// options, so the types the library asserts on don't depend on the options
+// chosen for the caller's values
+func (py *PureGoPython) internalToGo(obj PyObject) (interface{}, error) {
+	return py.pythonToGoDepth(obj, &decodeOptions{}, 0)
+}
+
+// pythonToGoDepth converts Python objects to Go values, tracking container
+// nesting depth. obj is borrowed: conversion never releases a reference to it
+// or to the items it reads from containers, so callers may pass borrowed
+// references from PyList_GetItem, PyTuple_GetItem or PyDict_GetItem.
+func (py *PureGoPython) pythonToGoDepth(obj PyObject, opts *decodeOptions, depth int) (interface{}, error) {
 	if py.isNone(obj) {
 		return nil, nil
 	}
 
+	if err := py.checkConversionDepth(depth); err != nil {
+		return nil, err
+	}
+
 	// Check string first
 	if py.isString(obj) {
-		cStr := py.pyUnicodeAsUTF8(uintptr(obj))
-		if cStr == nil {
-			return nil, fmt.Errorf("failed to convert Python string to UTF-8")
-		}
-		return cStringToGoString(cStr), nil
+		return py.pythonStringToGo(obj)
 	}
 
 	// Check bool first (since bool is a subclass of int in Python)
 	if py.isBool(obj) {
-		return py.pyLongAsLong(uintptr(obj)) != 0, nil
+		return py.pythonBoolToGo(obj)
 	}
 
 	// Check integer
 	if py.isInt(obj) {
-		return py.pyLongAsLong(uintptr(obj)), nil
+		value, err := py.pythonIntToGo(obj)
+		if err != nil || !opts.intAsPlatformInt {
+			return value, err
+		}
+		return toPlatformInt(value)
 	}
 
 	// Check float
@@ -146,40 +754,656 @@ func (py *PureGoPython) pythonToGo(obj PyObject) (interface{}, error) {
 		return py.pyFloatAsDouble(uintptr(obj)), nil
 	}
 
+	// Check complex
+	if py.isComplex(obj) {
+		if err := py.requireFeature("complex numbers"); err != nil {
+			return nil, err
+		}
+		return complex(py.pyComplexRealAsDouble(uintptr(obj)), py.pyComplexImagAsDouble(uintptr(obj))), nil
+	}
+
+	// Check timedelta
+	if py.isTimedelta(obj) {
+		return py.pythonTimedeltaToGo(obj)
+	}
+
+	// Check datetime, date and time
+	if py.isDatetime(obj) {
+		return py.pythonDatetimeToGo(obj)
+	}
+	if py.isDate(obj) {
+		return py.pythonDateToGo(obj)
+	}
+	if py.isTimeOfDay(obj) {
+		return py.pythonTimeOfDayToGo(obj)
+	}
+
+	// Check uuid.UUID
+	if py.isUUID(obj) {
+		str, err := py.objectToString(uintptr(obj))
+		if err != nil {
+			return nil, err
+		}
+		return UUID(str), nil
+	}
+
+	// Check bytes, bytearray and memoryview
+	if py.isBytes(obj) {
+		return py.pythonBytesToGo(obj)
+	}
+	if py.isByteArray(obj) {
+		if err := py.requireFeature("bytearray"); err != nil {
+			return nil, err
+		}
+		size := py.pyByteArraySize(uintptr(obj))
+		if size < 0 {
+			return nil, fmt.Errorf("failed to get bytearray size: %w", py.getPythonError())
+		}
+		return cBytesToGoBytes(py.pyByteArrayAsString(uintptr(obj)), size), nil
+	}
+	if py.isMemoryView(obj) {
+		data, err := py.callNoArgsMethod(uintptr(obj), "tobytes")
+		if err != nil {
+			return nil, err
+		}
+		defer py.safeDecRef(data)
+		return py.pythonBytesToGo(PyObject(data))
+	}
+
 	// Check list
 	if py.isList(obj) {
-		return py.pythonListToSlice(obj)
+		return py.pythonListToSlice(obj, opts, depth)
+	}
+
+	// Check tuple
+	if py.isTuple(obj) {
+		return py.pythonTupleToSlice(obj, opts, depth)
 	}
 
 	// Check dict
 	if py.isDict(obj) {
-		return py.pythonDictToMap(obj)
+		if py.hasNonStringKeys(obj) {
+			if opts.preserveDictOrder {
+				return nil, errors.New("dict with non-string keys cannot be converted to OrderedDictValue")
+			}
+			return py.pythonDictToAnyMap(obj, opts, depth)
+		}
+		if opts.preserveDictOrder {
+			return py.pythonDictToOrdered(obj, opts, depth)
+		}
+		return py.pythonDictToMap(obj, opts, depth)
+	}
+
+	// Check set and frozenset; element order is undefined
+	if py.isSet(obj) {
+		return py.pythonIterableToSlice(obj, opts, depth)
+	}
+
+	// Check numpy.ndarray
+	if py.isNDArray(obj) {
+		return py.ndarrayToGo(obj, opts, depth)
+	}
+
+	// Array-like numeric scalars (NumPy, pandas) when enabled
+	if opts.numericFallback && py.isArrayLike(obj) {
+		if value, ok := py.arrayLikeToNumber(obj); ok {
+			return value, nil
+		}
 	}
 
 	typeName := py.getTypeName(obj)
+	if opts.reprFallback {
+		repr, err := py.objectRepr(uintptr(obj))
+		if err == nil {
+			return UnknownValue{TypeName: typeName, Repr: repr}, nil
+		}
+		return nil, fmt.Errorf("unsupported Python type: %s (repr failed: %v)", typeName, err)
+	}
 	return nil, fmt.Errorf("unsupported Python type: %s", typeName)
 }
 
+// isArrayLike reports whether obj exposes __array__ or the buffer protocol
+func (py *PureGoPython) isArrayLike(obj PyObject) bool {
+	if py.pyObjectHasAttrString(uintptr(obj), stringToCString("__array__")) != 0 {
+		return true
+	}
+	return py.pyObjectCheckBuffer != nil && py.pyObjectCheckBuffer(uintptr(obj)) != 0
+}
+
+// arrayLikeToNumber extracts a scalar from an array-like object via the number
+// protocol, trying an integer first and a float second
+func (py *PureGoPython) arrayLikeToNumber(obj PyObject) (interface{}, bool) {
+	if index := py.pyNumberIndex(uintptr(obj)); index != 0 {
+		defer py.safeDecRef(index)
+		value, err := py.pythonIntToGo(PyObject(index))
+		return value, err == nil
+	}
+	py.pyErrClear()
+
+	if float := py.pyNumberFloat(uintptr(obj)); float != 0 {
+		defer py.safeDecRef(float)
+		return py.pyFloatAsDouble(float), true
+	}
+	py.pyErrClear()
+
+	return nil, false
+}
+
+// Py_buffer request flags, from CPython's object.h
+const (
+	pyBUFFormat       = 0x0004
+	pyBUFCContiguous  = 0x0038
+	pyBUFContigFormat = pyBUFCContiguous | pyBUFFormat
+)
+
+// ndarrayToGo converts a NumPy array. One-dimensional arrays of ints and
+// floats in native byte order are copied straight out of the buffer protocol
+// into an []int64 or []float64, without creating a Python object per element.
+// Other arrays, such as multi-dimensional, strided, bool or object arrays,
+// are converted through tolist().
+func (py *PureGoPython) ndarrayToGo(obj PyObject, opts *decodeOptions, depth int) (interface{}, error) {
+	if values, ok := py.ndarrayFromBuffer(obj); ok {
+		return values, nil
+	}
+
+	list, err := py.callNoArgsMethod(uintptr(obj), "tolist")
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert ndarray: %w", err)
+	}
+	defer py.safeDecRef(list)
+	return py.pythonToGoDepth(PyObject(list), opts, depth)
+}
+
+// ndarrayFromBuffer copies a contiguous one-dimensional numeric array out of
+// its buffer. It returns false, with no Python error set, if the array can't
+// be read this way.
+func (py *PureGoPython) ndarrayFromBuffer(obj PyObject) (interface{}, bool) {
+	if py.requireFeature("buffer protocol") != nil {
+		return nil, false
+	}
+
+	var view pyBuffer
+	if py.pyObjectGetBuffer(uintptr(obj), &view, pyBUFContigFormat) != 0 {
+		py.pyErrClear()
+		return nil, false
+	}
+	defer py.pyBufferRelease(&view)
+
+	if view.ndim != 1 || view.itemsize <= 0 || view.len%view.itemsize != 0 {
+		return nil, false
+	}
+	kind, ok := nativeBufferKind(cStringToGoString(view.format))
+	if !ok {
+		return nil, false
+	}
+	n := view.len / view.itemsize
+
+	switch kind {
+	case 'f':
+		switch view.itemsize {
+		case 4:
+			return widenBuffer[float32, float64](view.buf, n), true
+		case 8:
+			return widenBuffer[float64, float64](view.buf, n), true
+		}
+	case 'i':
+		switch view.itemsize {
+		case 1:
+			return widenBuffer[int8, int64](view.buf, n), true
+		case 2:
+			return widenBuffer[int16, int64](view.buf, n), true
+		case 4:
+			return widenBuffer[int32, int64](view.buf, n), true
+		case 8:
+			return widenBuffer[int64, int64](view.buf, n), true
+		}
+	case 'u':
+		switch view.itemsize {
+		case 1:
+			return widenBuffer[uint8, int64](view.buf, n), true
+		case 2:
+			return widenBuffer[uint16, int64](view.buf, n), true
+		case 4:
+			return widenBuffer[uint32, int64](view.buf, n), true
+		case 8:
+			for _, v := range unsafe.Slice((*uint64)(view.buf), n) {
+				if v > math.MaxInt64 {
+					return nil, false
+				}
+			}
+			return widenBuffer[uint64, int64](view.buf, n), true
+		}
+	}
+	return nil, false
+}
+
+// nativeBufferKind classifies a struct-module buffer format describing a
+// single native-endian number as 'i' (signed), 'u' (unsigned) or 'f' (float)
+func nativeBufferKind(format string) (byte, bool) {
+	if len(format) == 2 {
+		switch format[0] {
+		case '@', '=':
+		case '<':
+			if binary.NativeEndian.Uint16([]byte{1, 0}) != 1 {
+				return 0, false
+			}
+		case '>', '!':
+			if binary.NativeEndian.Uint16([]byte{0, 1}) != 1 {
+				return 0, false
+			}
+		default:
+			return 0, false
+		}
+		format = format[1:]
+	}
+	if len(format) != 1 {
+		return 0, false
+	}
+
+	switch format[0] {
+	case 'b', 'h', 'i', 'l', 'q', 'n':
+		return 'i', true
+	case 'B', 'H', 'I', 'L', 'Q', 'N':
+		return 'u', true
+	case 'f', 'd':
+		return 'f', true
+	}
+	return 0, false
+}
+
+// widenBuffer copies n values of type T starting at buf into a new []R
+func widenBuffer[T, R int8 | int16 | int32 | int64 | uint8 | uint16 | uint32 | uint64 | float32 | float64](buf unsafe.Pointer, n int) []R {
+	result := make([]R, n)
+	if n == 0 {
+		return result
+	}
+	for i, v := range unsafe.Slice((*T)(buf), n) {
+		result[i] = R(v)
+	}
+	return result
+}
+
+// pythonTimedeltaToGo converts a Python datetime.timedelta to a Go duration
+func (py *PureGoPython) pythonTimedeltaToGo(obj PyObject) (time.Duration, error) {
+	parts, err := py.intAttrs(obj, "timedelta", "days", "seconds", "microseconds")
+	if err != nil {
+		return 0, err
+	}
+
+	const maxDays = int64(math.MaxInt64 / int64(24*time.Hour))
+	if parts[0] > maxDays || parts[0] < -maxDays {
+		return 0, fmt.Errorf("timedelta of %d days overflows time.Duration", parts[0])
+	}
+
+	return time.Duration(parts[0])*24*time.Hour +
+		time.Duration(parts[1])*time.Second +
+		time.Duration(parts[2])*time.Microsecond, nil
+}
+
+// intAttrs reads integer attributes of obj, such as the fields of a datetime
+func (py *PureGoPython) intAttrs(obj PyObject, typeName string, names ...string) ([]int64, error) {
+	values := make([]int64, len(names))
+	for i, name := range names {
+		attr := py.getAttrString(uintptr(obj), name)
+		if attr == 0 {
+			return nil, fmt.Errorf("failed to read %s.%s", typeName, name)
+		}
+		values[i] = py.pyLongAsLong(attr)
+		py.safeDecRef(attr)
+	}
+	return values, nil
+}
+
+// pythonDatetimeToGo converts a Python datetime.datetime to a Go time. Aware
+// datetimes keep their UTC offset and zone name; naive ones are taken as UTC.
+func (py *PureGoPython) pythonDatetimeToGo(obj PyObject) (time.Time, error) {
+	parts, err := py.intAttrs(obj, "datetime", "year", "month", "day", "hour", "minute", "second", "microsecond")
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc, err := py.pythonLocation(obj)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(int(parts[0]), time.Month(parts[1]), int(parts[2]),
+		int(parts[3]), int(parts[4]), int(parts[5]), int(parts[6])*1000, loc), nil
+}
+
+// pythonDateToGo converts a Python datetime.date to midnight UTC on that day
+func (py *PureGoPython) pythonDateToGo(obj PyObject) (time.Time, error) {
+	parts, err := py.intAttrs(obj, "date", "year", "month", "day")
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(int(parts[0]), time.Month(parts[1]), int(parts[2]), 0, 0, 0, 0, time.UTC), nil
+}
+
+// pythonTimeOfDayToGo converts a Python datetime.time to a Go time on
+// January 1 of year 0, the date time.Parse uses for layouts without one.
+// timeToPython turns times on that date back into a datetime.time.
+func (py *PureGoPython) pythonTimeOfDayToGo(obj PyObject) (time.Time, error) {
+	parts, err := py.intAttrs(obj, "time", "hour", "minute", "second", "microsecond")
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc, err := py.pythonLocation(obj)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(0, time.January, 1, int(parts[0]), int(parts[1]), int(parts[2]), int(parts[3])*1000, loc), nil
+}
+
+// pythonLocation returns the Go location matching the tzinfo of a Python
+// datetime or time: UTC when it is naive, otherwise a fixed zone with its
+// UTC offset and name
+func (py *PureGoPython) pythonLocation(obj PyObject) (*time.Location, error) {
+	offsetObj, err := py.callNoArgsMethod(uintptr(obj), "utcoffset")
+	if err != nil {
+		return nil, err
+	}
+	defer py.safeDecRef(offsetObj)
+	if py.isNone(PyObject(offsetObj)) {
+		return time.UTC, nil
+	}
+
+	offset, err := py.pythonTimedeltaToGo(PyObject(offsetObj))
+	if err != nil {
+		return nil, err
+	}
+
+	name := ""
+	if nameObj, err := py.callNoArgsMethod(uintptr(obj), "tzname"); err == nil {
+		if py.isString(PyObject(nameObj)) {
+			name, _ = py.pythonStringToGo(PyObject(nameObj))
+		}
+		py.safeDecRef(nameObj)
+	}
+	if offset == 0 && name == "UTC" {
+		return time.UTC, nil
+	}
+	return time.FixedZone(name, int(offset/time.Second)), nil
+}
+
+// callNoArgsMethod calls obj.name() and returns a new reference to the result
+func (py *PureGoPython) callNoArgsMethod(obj uintptr, name string) (uintptr, error) {
+	method := py.getAttrString(obj, name)
+	if method == 0 {
+		return 0, fmt.Errorf("object has no method '%s'", name)
+	}
+	defer py.safeDecRef(method)
+
+	result := py.pyObjectCallObject(method, 0)
+	if result == 0 {
+		return 0, fmt.Errorf("failed to call %s(): %w", name, py.getPythonError())
+	}
+	return result, nil
+}
+
+// toPlatformInt narrows a value from pythonIntToGo to a Go int for
+// SetIntAsPlatformInt
+func toPlatformInt(value interface{}) (int, error) {
+	switch v := value.(type) {
+	case int:
+		return v, nil
+	case int64:
+		if v >= math.MinInt && v <= math.MaxInt {
+			return int(v), nil
+		}
+	case *big.Int:
+		if v.IsInt64() && v.Int64() >= math.MinInt && v.Int64() <= math.MaxInt {
+			return int(v.Int64()), nil
+		}
+	}
+	return 0, fmt.Errorf("int %v overflows Go int (%d bits)", value, strconv.IntSize)
+}
+
+// pythonIntToGo converts a Python int to an int64, or to a *big.Int when the
+// value does not fit in 64 bits
+func (py *PureGoPython) pythonIntToGo(obj PyObject) (interface{}, error) {
+	value := py.pyLongAsLong(uintptr(obj))
+	if value != -1 || py.pyErrOccurred() == 0 {
+		return value, nil
+	}
+
+	// OverflowError: Python ints are arbitrary precision, so go through the
+	// decimal string representation instead
+	py.pyErrClear()
+	str, err := py.objectToString(uintptr(obj))
+	if err != nil {
+		return nil, err
+	}
+	bigValue, ok := new(big.Int).SetString(str, 10)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse Python int %q", str)
+	}
+	return bigValue, nil
+}
+
+// pythonStringToGo converts a Python str to a Go string. Strings containing
+// lone surrogates, such as filenames from os.listdir that are not valid UTF-8,
+// are encoded with surrogateescape so the Go string holds the original bytes.
+func (py *PureGoPython) pythonStringToGo(obj PyObject) (string, error) {
+	// The UTF-8 buffer is cached by the str object, so this copies it once
+	// and keeps embedded NUL characters
+	var size int
+	if cStr := py.pyUnicodeAsUTF8AndSize(uintptr(obj), &size); cStr != nil {
+		return string(unsafe.Slice(cStr, size)), nil
+	}
+	py.pyErrClear()
+
+	encoded := py.pyUnicodeAsEncodedString(uintptr(obj), stringToCString("utf-8"), stringToCString("surrogateescape"))
+	if encoded == 0 {
+		return "", fmt.Errorf("failed to convert Python string to UTF-8: %w", py.getPythonError())
+	}
+	defer py.safeDecRef(encoded)
+
+	b, err := py.pythonBytesToGo(PyObject(encoded))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// pythonBytesToGo converts a Python bytes object to a Go byte slice
+func (py *PureGoPython) pythonBytesToGo(obj PyObject) ([]byte, error) {
+	var buffer *byte
+	var size int
+	if py.pyBytesAsStringAndSize(uintptr(obj), &buffer, &size) != 0 {
+		return nil, fmt.Errorf("failed to read Python bytes: %w", py.getPythonError())
+	}
+	return cBytesToGoBytes(buffer, size), nil
+}
+
 // pythonListToSlice converts a Python list to a Go slice
-func (py *PureGoPython) pythonListToSlice(obj PyObject) ([]interface{}, error) {
+func (py *PureGoPython) pythonListToSlice(obj PyObject, opts *decodeOptions, depth int) (_ []interface{}, err error) {
 	size := py.pyListSize(uintptr(obj))
+	if size < 0 {
+		return nil, fmt.Errorf("failed to get list size: %w", py.getPythonError())
+	}
 	result := make([]interface{}, size)
-	
+	var skipped ConversionErrors
+	defer func() {
+		// Release the placeholders of elements skipped before the failure
+		if err != nil && !isPartial(err) {
+			CloseAll(result)
+		}
+	}()
+
 	for i := 0; i < size; i++ {
+		// PyList_GetItem returns a borrowed reference. Converting an item
+		// can run Python code (__index__, tolist(), repr()) that may shrink
+		// the list or replace the item, so hold a reference while converting.
 		item := py.pyListGetItem(uintptr(obj), i)
-		val, err := py.pythonToGo(PyObject(item))
+		if item == 0 {
+			return nil, fmt.Errorf("list changed size during conversion: %w", py.getPythonError())
+		}
+		py.pyIncRef(item)
+		val, err := py.pythonToGoDepth(PyObject(item), opts, depth+1)
+		if err != nil {
+			val, err = py.skipElement(opts, item, val, err, fmt.Sprintf("list item %d", i), &skipped)
+		}
+		py.safeDecRef(item)
 		if err != nil {
+			if errors.Is(err, ErrMaxDepthExceeded) {
+				return nil, err
+			}
 			return nil, fmt.Errorf("failed to convert list item %d: %v", i, err)
 		}
 		result[i] = val
 	}
-	
-	return result, nil
+
+	return result, opts.skippedErrors(skipped)
+}
+
+// skipElement handles err from converting the container element item, found
+// at where. The partial value of a nested container that skipped elements is
+// kept and its errors are added to skipped. With SetSkipUnconvertible
+// enabled, other errors are added to skipped and the placeholder is returned
+// instead; otherwise err is returned.
+func (py *PureGoPython) skipElement(opts *decodeOptions, item uintptr, val interface{}, err error, where string, skipped *ConversionErrors) (interface{}, error) {
+	if isPartial(err) {
+		*skipped = appendSkipped(*skipped, where, err)
+		return val, nil
+	}
+	if !opts.skipUnconvertible.Enabled || errors.Is(err, ErrMaxDepthExceeded) {
+		return nil, err
+	}
+
+	if py.pyErrOccurred() != 0 {
+		py.pyErrClear()
+	}
+	*skipped = append(*skipped, fmt.Errorf("%s: %w", where, err))
+	if opts.skipUnconvertible.AsHandle {
+		py.pyIncRef(item)
+		return py.newHandle(item), nil
+	}
+	return nil, nil
+}
+
+// isPartial reports whether err only lists the elements skipped by
+// SetSkipUnconvertible, so the value converted along with it is usable
+func isPartial(err error) bool {
+	var skipped ConversionErrors
+	return errors.As(err, &skipped)
+}
+
+// appendSkipped adds the elements listed by the ConversionErrors in err, if
+// any, to skipped, prefixed with where the partially converted value was
+func appendSkipped(skipped ConversionErrors, where string, err error) ConversionErrors {
+	var nested ConversionErrors
+	if errors.As(err, &nested) {
+		for _, nestedErr := range nested {
+			skipped = append(skipped, fmt.Errorf("%s: %w", where, nestedErr))
+		}
+	}
+	return skipped
+}
+
+// skippedErrors returns the errors of the elements skipped while converting
+// a container, if SetSkipUnconvertible collects them
+func (opts *decodeOptions) skippedErrors(skipped ConversionErrors) error {
+	if len(skipped) == 0 || !opts.skipUnconvertible.CollectErrors {
+		return nil
+	}
+	return skipped
+}
+
+// pythonTupleToSlice converts a Python tuple to a Go slice
+func (py *PureGoPython) pythonTupleToSlice(obj PyObject, opts *decodeOptions, depth int) (_ []interface{}, err error) {
+	size := py.pyTupleSize(uintptr(obj))
+	if size < 0 {
+		return nil, fmt.Errorf("failed to get tuple size: %w", py.getPythonError())
+	}
+	result := make([]interface{}, size)
+	var skipped ConversionErrors
+	defer func() {
+		// Release the placeholders of elements skipped before the failure
+		if err != nil && !isPartial(err) {
+			CloseAll(result)
+		}
+	}()
+
+	for i := 0; i < size; i++ {
+		// PyTuple_GetItem returns a borrowed reference, which stays valid
+		// because tuples are immutable and obj is kept alive by the caller
+		item := py.pyTupleGetItem(uintptr(obj), i)
+		val, err := py.pythonToGoDepth(PyObject(item), opts, depth+1)
+		if err != nil {
+			val, err = py.skipElement(opts, item, val, err, fmt.Sprintf("tuple item %d", i), &skipped)
+		}
+		if err != nil {
+			if errors.Is(err, ErrMaxDepthExceeded) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("failed to convert tuple item %d: %v", i, err)
+		}
+		result[i] = val
+	}
+
+	return result, opts.skippedErrors(skipped)
+}
+
+// pythonIterableToSlice converts every item produced by iterating obj to a
+// Go slice
+func (py *PureGoPython) pythonIterableToSlice(obj PyObject, opts *decodeOptions, depth int) (_ []interface{}, err error) {
+	iterator := py.pyObjectGetIter(uintptr(obj))
+	if iterator == 0 {
+		return nil, fmt.Errorf("object is not iterable: %w", py.getPythonError())
+	}
+	defer py.safeDecRef(iterator)
+
+	result := []interface{}{}
+	var skipped ConversionErrors
+	defer func() {
+		// Release the placeholders of elements skipped before the failure
+		if err != nil && !isPartial(err) {
+			CloseAll(result)
+		}
+	}()
+	for i := 0; ; i++ {
+		// PyIter_Next returns a new reference, or NULL when exhausted or on error
+		item := py.pyIterNext(iterator)
+		if item == 0 {
+			break
+		}
+		val, err := py.pythonToGoDepth(PyObject(item), opts, depth+1)
+		if err != nil {
+			val, err = py.skipElement(opts, item, val, err, fmt.Sprintf("item %d", i), &skipped)
+		}
+		py.safeDecRef(item)
+		if err != nil {
+			if errors.Is(err, ErrMaxDepthExceeded) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("failed to convert item %d: %v", i, err)
+		}
+		result = append(result, val)
+	}
+
+	if py.pyErrOccurred() != 0 {
+		return nil, fmt.Errorf("iteration failed: %w", py.getPythonError())
+	}
+	return result, opts.skippedErrors(skipped)
 }
 
 // pythonDictToMap converts a Python dictionary to a Go map
-func (py *PureGoPython) pythonDictToMap(obj PyObject) (map[string]interface{}, error) {
-	result := make(map[string]interface{})
+func (py *PureGoPython) pythonDictToMap(obj PyObject, opts *decodeOptions, depth int) (map[string]interface{}, error) {
+	entries, err := py.pythonDictToOrdered(obj, opts, depth)
+	if entries == nil {
+		return nil, err
+	}
+
+	// err holds the ConversionErrors of skipped values, if any
+	result := make(map[string]interface{}, len(entries))
+	for _, entry := range entries {
+		result[entry.Key] = entry.Value
+	}
+	return result, err
+}
+
+// pythonDictToOrdered converts a Python dictionary with string keys to its
+// entries in insertion order, the order PyDict_Keys returns them in
+func (py *PureGoPython) pythonDictToOrdered(obj PyObject, opts *decodeOptions, depth int) (_ OrderedDictValue, err error) {
 	keys := py.pyDictKeys(uintptr(obj))
 	if keys == 0 {
 		return nil, fmt.Errorf("failed to get dict keys")
@@ -187,40 +1411,123 @@ func (py *PureGoPython) pythonDictToMap(obj PyObject) (map[string]interface{}, e
 	defer py.safeDecRef(keys)
 
 	size := py.pyListSize(keys)
+	result := make(OrderedDictValue, 0, size)
+	var skipped ConversionErrors
+	defer func() {
+		// Release the placeholders of elements skipped before the failure
+		if err != nil && !isPartial(err) {
+			CloseAll(result)
+		}
+	}()
 	for i := 0; i < size; i++ {
+		// PyList_GetItem and PyDict_GetItem return borrowed references
 		keyObj := py.pyListGetItem(keys, i)
 		if !py.isString(PyObject(keyObj)) {
-			continue // Skip non-string keys
+			return nil, fmt.Errorf("dict key of type %s is not a string", py.getTypeName(PyObject(keyObj)))
+		}
+		key, err := py.pythonStringToGo(PyObject(keyObj))
+		if err != nil {
+			return nil, err
 		}
 
-		cKey := py.pyUnicodeAsUTF8(keyObj)
-		if cKey == nil {
+		valObj := py.pyDictGetItem(uintptr(obj), keyObj)
+		if valObj == 0 {
 			continue
 		}
 
-		// Convert key to Go string
-		key := ""
-		for j := 0; ; j++ {
-			b := (*byte)(unsafe.Add(unsafe.Pointer(cKey), j))
-			if *b == 0 {
-				break
+		// Hold the borrowed value in case converting it modifies the dict
+		py.pyIncRef(valObj)
+		val, err := py.pythonToGoDepth(PyObject(valObj), opts, depth+1)
+		if err != nil {
+			val, err = py.skipElement(opts, valObj, val, err, fmt.Sprintf("dict value for key '%s'", key), &skipped)
+		}
+		py.safeDecRef(valObj)
+		if err != nil {
+			if errors.Is(err, ErrMaxDepthExceeded) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("failed to convert dict value for key '%s': %v", key, err)
+		}
+		result = append(result, KeyValue{Key: key, Value: val})
+	}
+
+	return result, opts.skippedErrors(skipped)
+}
+
+// hasNonStringKeys reports whether a Python dictionary has any key that is
+// not a str
+func (py *PureGoPython) hasNonStringKeys(obj PyObject) bool {
+	keys := py.pyDictKeys(uintptr(obj))
+	if keys == 0 {
+		py.pyErrClear()
+		return false
+	}
+	defer py.safeDecRef(keys)
+
+	for i := 0; i < py.pyListSize(keys); i++ {
+		if !py.isString(PyObject(py.pyListGetItem(keys, i))) {
+			return true
+		}
+	}
+	return false
+}
+
+// pythonDictToAnyMap converts a Python dictionary with non-string keys, such
+// as ints, to a Go map keyed by the converted keys. Keys that convert to
+// values Go cannot use as map keys (tuples become slices) are an error rather
+// than being dropped.
+func (py *PureGoPython) pythonDictToAnyMap(obj PyObject, opts *decodeOptions, depth int) (_ map[interface{}]interface{}, err error) {
+	keys := py.pyDictKeys(uintptr(obj))
+	if keys == 0 {
+		return nil, fmt.Errorf("failed to get dict keys")
+	}
+	defer py.safeDecRef(keys)
+
+	size := py.pyListSize(keys)
+	result := make(map[interface{}]interface{}, size)
+	var skipped ConversionErrors
+	defer func() {
+		// Release the placeholders of elements skipped before the failure
+		if err != nil && !isPartial(err) {
+			CloseAll(result)
+		}
+	}()
+	for i := 0; i < size; i++ {
+		// PyList_GetItem and PyDict_GetItem return borrowed references
+		keyObj := py.pyListGetItem(keys, i)
+		key, err := py.pythonToGoDepth(PyObject(keyObj), opts, depth+1)
+		if err != nil {
+			if errors.Is(err, ErrMaxDepthExceeded) {
+				return nil, err
 			}
-			key += string(*b)
+			return nil, fmt.Errorf("failed to convert dict key %d: %v", i, err)
+		}
+		if _, isBig := key.(*big.Int); isBig || (key != nil && !reflect.TypeOf(key).Comparable()) {
+			return nil, fmt.Errorf("dict key of type %s cannot be used as a Go map key", py.getTypeName(PyObject(keyObj)))
 		}
 
-		valObj := py.pyDictGetItemString(uintptr(obj), cKey)
+		valObj := py.pyDictGetItem(uintptr(obj), keyObj)
 		if valObj == 0 {
 			continue
 		}
 
-		val, err := py.pythonToGo(PyObject(valObj))
+		// Hold the borrowed value in case converting it modifies the dict
+		py.pyIncRef(valObj)
+		val, err := py.pythonToGoDepth(PyObject(valObj), opts, depth+1)
 		if err != nil {
-			return nil, fmt.Errorf("failed to convert dict value for key '%s': %v", key, err)
+			val, err = py.skipElement(opts, valObj, val, err, fmt.Sprintf("dict value for key %v", key), &skipped)
+		}
+		py.safeDecRef(valObj)
+		if err != nil {
+			if errors.Is(err, ErrMaxDepthExceeded) {
+				return nil, err
+			}
+			return nil, fmt.Errorf("failed to convert dict value for key %v: %v", key, err)
 		}
 		result[key] = val
 	}
-	
-	return result, nil
+
+	return result, opts.skippedErrors(skipped)
 }
 
 // buildArgumentTuple converts Go arguments to a Python tuple for function calls
@@ -234,7 +1541,7 @@ func (py *PureGoPython) buildArgumentTuple(args ...interface{}) (PyObject, error
 		pyArg, err := py.goToPython(arg)
 		if err != nil {
 			py.safeDecRef(argTuple)
-			return 0, fmt.Errorf("failed to convert argument %d: %v", i, err)
+			return 0, fmt.Errorf("failed to convert argument %d: %w", i, err)
 		}
 
 		// PyTuple_SetItem steals the reference
@@ -245,4 +1552,72 @@ func (py *PureGoPython) buildArgumentTuple(args ...interface{}) (PyObject, error
 	}
 
 	return PyObject(argTuple), nil
-}
\ No newline at end of file
+}
+
+// syncByteArrays copies the contents of bytearray arguments back into the
+// ByteArray slices they were created from, after Python may have mutated them
+func (py *PureGoPython) syncByteArrays(argTuple PyObject, args []interface{}, kwargsDict PyObject, kwargs map[string]interface{}) {
+	for i, arg := range args {
+		if b, ok := arg.(ByteArray); ok {
+			// PyTuple_GetItem returns a borrowed reference
+			py.copyByteArray(b, py.pyTupleGetItem(uintptr(argTuple), i))
+		}
+	}
+	for key, arg := range kwargs {
+		if b, ok := arg.(ByteArray); ok {
+			// PyDict_GetItemString returns a borrowed reference
+			py.copyByteArray(b, py.pyDictGetItemString(uintptr(kwargsDict), stringToCString(key)))
+		}
+	}
+}
+
+// copyByteArray copies the contents of a Python bytearray into b
+func (py *PureGoPython) copyByteArray(b ByteArray, obj uintptr) {
+	if len(b) == 0 || obj == 0 || py.pyByteArraySize == nil || !py.isByteArray(PyObject(obj)) {
+		return
+	}
+	size := py.pyByteArraySize(obj)
+	copy(b, cBytesToGoBytes(py.pyByteArrayAsString(obj), size))
+}
+
+// identityHelper builds the identity function RoundTrip passes values through
+const identityHelper = `
+def make_identity():
+    return lambda value: value
+`
+
+// RoundTrip converts value to Python, passes it through an identity function
+// and converts the result back to Go. It is meant for tests: the result
+// shows exactly how a value survives both conversions, e.g. that an int
+// comes back as int64 or a SetValue as an unordered []interface{}. The
+// identity function is created once and reused.
+//
+// Example:
+//
+//	got, err := gopython.RoundTrip(py, gopython.UUID("123e4567-e89b-12d3-a456-426614174000"))
+//	if err != nil || got != want {
+//	    t.Errorf("round trip = %#v, %v", got, err)
+//	}
+func RoundTrip(py *PureGoPython, value interface{}) (interface{}, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+
+	return py.withGILReturn(func() (interface{}, error) {
+		if py.identityFunc == nil {
+			identityObj, err := py.callHelper(identityHelper, "make_identity")
+			if err != nil {
+				return nil, err
+			}
+			py.identityFunc = py.newHandle(identityObj)
+		}
+
+		resultObj, err := py.callObject(py.identityFunc.obj, value)
+		if err != nil {
+			return nil, err
+		}
+		defer py.safeDecRef(resultObj)
+
+		return py.pythonToGo(PyObject(resultObj))
+	})
+}
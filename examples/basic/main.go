@@ -1,9 +1,16 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"log"
+	"math"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"github.com/develerltd/gopython310"
 )
@@ -28,10 +35,24 @@ func main() {
 	if err := py.Initialize(); err != nil {
 		log.Fatalf("Failed to initialize Python: %v", err)
 	}
-	defer py.Finalize()
+	defer py.Close()
 
 	fmt.Printf("Python interpreter initialized: %v\n", py.IsInitialized())
 
+	if major, minor, micro, err := py.Version(); err != nil {
+		fmt.Printf("Error reading Python version: %v\n", err)
+	} else {
+		fmt.Printf("Python version: %d.%d.%d\n", major, minor, micro)
+	}
+	if err := py.VerifyVersion(3, 10); err != nil {
+		fmt.Printf("Version check: %v\n", err)
+	}
+	if missing := py.MissingSymbols(); len(missing) > 0 {
+		fmt.Printf("Missing libpython symbols (some features unavailable): %v\n", missing)
+	} else {
+		fmt.Println("All libpython symbols registered")
+	}
+
 	// Phase 2: Execute Python strings
 	fmt.Println("\n=== Phase 2: Execution Layer ===")
 
@@ -67,6 +88,39 @@ print("This won't execute")
 		fmt.Printf("File execution error: %v\n", err)
 	}
 
+	// Test streaming a script's output line by line, including a final
+	// partial line and output printed before the script fails
+	if dir, err := os.MkdirTemp("", "gopython-streaming"); err == nil {
+		script := filepath.Join(dir, "progress.py")
+		os.WriteFile(script, []byte("import sys\nfor step in range(3):\n    print(f'step {step}')\nprint('careful', file=sys.stderr)\nsys.stdout.write('done')\nraise RuntimeError('late failure')\n"), 0o644)
+		var lines []string
+		err := py.RunFileStreaming(script, func(stream, line string) {
+			lines = append(lines, stream+": "+line)
+		})
+		fmt.Printf("RunFileStreaming lines=%q failed=%v\n", lines, err != nil)
+
+		// The exception raised by a script is wrapped in the RunFile error
+		failing := filepath.Join(dir, "failing.py")
+		os.WriteFile(failing, []byte("raise KeyError('missing')\n"), 0o644)
+		var scriptErr *gopython.PyError
+		if err := py.RunFile(failing); errors.As(err, &scriptErr) {
+			fmt.Printf("RunFile raised %s: %s\n", scriptErr.Type, scriptErr.Message)
+		} else {
+			fmt.Printf("Expected a *PyError from RunFile, got %v\n", err)
+		}
+
+		// The script directory and __file__ are only set while the script runs
+		os.WriteFile(filepath.Join(dir, "sibling.py"), []byte("VALUE = 'from sibling'\n"), 0o644)
+		withSibling := filepath.Join(dir, "with_sibling.py")
+		os.WriteFile(withSibling, []byte("import os, sibling\nseen = [os.path.basename(__file__), sibling.VALUE]\n"), 0o644)
+		runErr := py.RunFile(withSibling)
+		seen, _ := py.GetGlobal("seen")
+		py.RunString(fmt.Sprintf("import sys\nrestored = ['__file__' not in globals(), %q not in sys.path]", dir))
+		restored, _ := py.GetGlobal("restored")
+		fmt.Printf("RunFile saw %v, restored __file__ and sys.path: %v (err: %v)\n", seen, restored, runErr)
+		os.RemoveAll(dir)
+	}
+
 	// Phase 3: Function calling and type conversion
 	fmt.Println("\n=== Phase 3: Function Calling ===")
 
@@ -88,6 +142,41 @@ def get_info():
 
 def greet(name):
     return f"Hello, {name}!"
+
+def fill_buffer(buf):
+    for i in range(len(buf)):
+        buf[i] = i * 2
+
+def enumerate_letters(letters):
+    return list(enumerate(letters))
+
+def plot(*, title="untitled", width=6.4, legend=False):
+    return {"title": title, "width": width, "legend": legend}
+
+class Point:
+    def __init__(self, x, y):
+        self.x = x
+        self.y = y
+
+def kwarg_order(**kwargs):
+    return list(kwargs)
+
+class bool_:
+    """Stand-in for numpy.bool_, a bool-like scalar that does not subclass int"""
+    def __init__(self, value):
+        self.value = value
+    def __bool__(self):
+        return self.value
+
+def mixed_bools():
+    return [True, False, bool_(True), bool_(False), 1, 0]
+
+def echo_datetime(dt):
+    return [dt.isoformat(), dt]
+
+def read_relative(path):
+    with open(path) as f:
+        return f.read()
 `
 	if err := py.RunString(moduleCode); err != nil {
 		log.Printf("Error creating module: %v", err)
@@ -121,6 +210,38 @@ def greet(name):
 		fmt.Printf("process_list(%v) = %v\n", list, result)
 	}
 
+	// Test spreading a slice into *args versus passing it as one list
+	if err := py.RunString("def describe_args(*args):\n    return [len(args), [type(a).__name__ for a in args]]"); err != nil {
+		fmt.Printf("Error defining describe_args: %v\n", err)
+	} else {
+		single, err1 := py.CallFunction("__main__", "describe_args", list)
+		spread, err2 := py.CallFunction("__main__", "describe_args", list...)
+		explicit, err3 := py.CallFunctionArgsSlice("__main__", "describe_args", list)
+		empty, err4 := py.CallFunctionArgsSlice("__main__", "describe_args", nil)
+		fmt.Printf("*args: single slice %v, spread %v, CallFunctionArgsSlice %v, nil slice %v (errs: %v %v %v %v)\n",
+			single, spread, explicit, empty, err1, err2, err3, err4)
+	}
+
+	// Test formatting float results with Go's strconv and with Python's repr
+	formatFloats := func() []string {
+		var formatted []string
+		for _, literal := range []string{"0.1", "2", "1e15", "inf"} {
+			text, err := py.ResultAsString("builtins", "float", literal)
+			if err != nil {
+				text = err.Error()
+			}
+			formatted = append(formatted, text)
+		}
+		return formatted
+	}
+	goFormatted := formatFloats()
+	py.SetFloatRepr(true)
+	reprFormatted := formatFloats()
+	py.SetFloatRepr(false)
+	greeting, err := py.ResultAsString("__main__", "greet", "strings")
+	fmt.Printf("ResultAsString floats %q, with SetFloatRepr %q, 0.1 identical in both modes: %v, strings unchanged: %q (err: %v)\n",
+		goFormatted, reprFormatted, goFormatted[0] == reprFormatted[0], greeting, err)
+
 	// Test function returning dictionary
 	result, err = py.CallFunction("__main__", "get_info")
 	if err != nil {
@@ -129,6 +250,15 @@ def greet(name):
 		fmt.Printf("get_info() = %v (type: %T)\n", result, result)
 	}
 
+	// Test bytearray argument mutated in place by Python
+	buffer := make([]byte, 4)
+	_, err = py.CallFunction("__main__", "fill_buffer", gopython.ByteArray(buffer))
+	if err != nil {
+		fmt.Printf("Error calling fill_buffer: %v\n", err)
+	} else {
+		fmt.Printf("fill_buffer mutated Go slice to %v\n", buffer)
+	}
+
 	// Test calling built-in modules
 	fmt.Println("\nTesting built-in module calls...")
 	result, err = py.CallFunction("math", "sqrt", 16.0)
@@ -138,6 +268,802 @@ def greet(name):
 		fmt.Printf("math.sqrt(16.0) = %v\n", result)
 	}
 
+	// Test booleans and None inside a list argument
+	if err := py.RunString(`
+def check_flags(flags):
+    return [flags == [True, False, None], flags[0] is True, flags[1] is False, flags[2] is None]
+`); err != nil {
+		fmt.Printf("Error defining check_flags: %v\n", err)
+	}
+	result, err = py.CallFunction("__main__", "check_flags", []interface{}{true, false, nil})
+	if err != nil {
+		fmt.Printf("Error calling check_flags: %v\n", err)
+	} else {
+		fmt.Printf("check_flags([true, false, nil]) = %v\n", result)
+	}
+
+	// Test a raw callback passing a non-convertible object straight through
+	err = py.RegisterCallbackRaw("passthrough", func(args []*gopython.PyHandle) (*gopython.PyHandle, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("passthrough expects 1 argument, got %d", len(args))
+		}
+		return args[0], nil
+	})
+	if err != nil {
+		fmt.Printf("Error registering passthrough: %v\n", err)
+	} else if err := py.RunString(`
+import gocallbacks, sys
+sentinel = object()
+before = sys.getrefcount(sentinel)
+for _ in range(100):
+    assert gocallbacks.passthrough(sentinel) is sentinel
+assert sys.getrefcount(sentinel) == before
+`); err != nil {
+		fmt.Printf("Error calling passthrough: %v\n", err)
+	} else {
+		fmt.Println("passthrough returned the same object without leaking references")
+	}
+
+	// Test that callables kept by Python after their release raise instead of
+	// reading freed memory
+	if err := py.RegisterCallback("short_lived", func(args []interface{}) (interface{}, error) {
+		return "alive", nil
+	}); err != nil {
+		fmt.Printf("Error registering short_lived: %v\n", err)
+	} else if err := py.RunString("import gocallbacks\nkept_callback = gocallbacks.short_lived\nimport gc"); err != nil {
+		fmt.Printf("Error keeping short_lived: %v\n", err)
+	} else {
+		py.UnregisterCallback("short_lived")
+		progress, _ := py.WithProgress(func(float64, string) {})
+		py.SetGlobal("kept_progress", progress)
+		progress.Close()
+		py.RunString("gc.collect()\nfiller = [bytes(64) for _ in range(10000)]")
+		_, callbackErr := py.CallFunction("__main__", "kept_callback")
+		_, progressErr := py.CallFunction("__main__", "kept_progress", 0.5, "half")
+		fmt.Printf("Released callables raise: callback %v, progress %v\n", callbackErr, progressErr)
+	}
+
+	// Test reporting progress from a long-running Python function
+	type progressReport struct {
+		pct float64
+		msg string
+	}
+	var reports []progressReport
+	if err := py.RunString("def train(epochs, progress=None):\n    for i in range(epochs):\n        progress((i + 1) / epochs, f'epoch {i + 1}')\n    progress(1)\n    return epochs"); err != nil {
+		fmt.Printf("Error defining train: %v\n", err)
+	} else if progress, err := py.WithProgress(func(pct float64, msg string) {
+		reports = append(reports, progressReport{pct, msg})
+	}); err != nil {
+		fmt.Printf("Error creating progress callable: %v\n", err)
+	} else {
+		_, err := py.CallFunctionKwargs("__main__", "train", []interface{}{4}, map[string]interface{}{"progress": progress})
+		progress.Close()
+		expected := []progressReport{{0.25, "epoch 1"}, {0.5, "epoch 2"}, {0.75, "epoch 3"}, {1, "epoch 4"}, {1, ""}}
+		fmt.Printf("WithProgress reported %v, as expected: %v (err: %v)\n", reports, reflect.DeepEqual(reports, expected), err)
+	}
+
+	// Library callables decode their arguments with the default options, so
+	// progress(1) still works while ints convert to int
+	reports = nil
+	py.SetIntAsPlatformInt(true)
+	if progress, err := py.WithProgress(func(pct float64, msg string) {
+		reports = append(reports, progressReport{pct, msg})
+	}); err != nil {
+		fmt.Printf("Error creating progress callable: %v\n", err)
+	} else {
+		_, err := py.CallFunction("__main__", "train", 2, progress)
+		progress.Close()
+		expected := []progressReport{{0.5, "epoch 1"}, {1, "epoch 2"}, {1, ""}}
+		fmt.Printf("WithProgress with SetIntAsPlatformInt reported %v, as expected: %v (err: %v)\n", reports, reflect.DeepEqual(reports, expected), err)
+	}
+	py.SetIntAsPlatformInt(false)
+
+	// Test unpacking a packed binary record with the struct module
+	packed := []byte{0xf9, 0xff, 0xff, 0xff, 0x01, 0, 0, 0, 0, 0, 0, 0x04, 0x40, 'x', 'a', 'b', 'c'}
+	result, err = py.CallFunction("struct", "unpack", "<i?dc3s", packed)
+	if err != nil {
+		fmt.Printf("Error calling struct.unpack: %v\n", err)
+	} else {
+		fields := result.([]interface{})
+		ok := len(fields) == 5 && fields[0] == int64(-7) && fields[1] == true && fields[2] == 2.5 &&
+			string(fields[3].([]byte)) == "x" && string(fields[4].([]byte)) == "abc"
+		fmt.Printf("struct.unpack(\"<i?dc3s\", ...) = %v (as expected: %v)\n", fields, ok)
+	}
+
+	// Test complex numbers in both directions
+	result, err = py.CallFunction("cmath", "sqrt", complex(-4, 0))
+	if err != nil {
+		fmt.Printf("Error calling cmath.sqrt: %v\n", err)
+	} else {
+		fmt.Printf("cmath.sqrt(-4+0i) = %v (type: %T)\n", result, result)
+	}
+
+	// Test datetime round-trip with a UTC offset
+	stamp := time.Date(2024, time.March, 5, 14, 30, 15, 123456000, time.FixedZone("EST", -5*3600))
+	result, err = py.CallFunction("__main__", "echo_datetime", stamp)
+	if err != nil {
+		fmt.Printf("Error calling echo_datetime: %v\n", err)
+	} else {
+		pair := result.([]interface{})
+		fmt.Printf("echo_datetime: Python saw %v, Go got back %v (equal: %v)\n", pair[0], pair[1], pair[1].(time.Time).Equal(stamp))
+	}
+
+	// Test keyword-only arguments filled from a Go struct
+	type PlotOptions struct {
+		Title  string  `py:"title,omitempty"`
+		Width  float64 `py:"width,omitempty"`
+		Legend bool    `py:"legend"`
+	}
+	result, err = py.CallFunctionStructKwargs("__main__", "plot", PlotOptions{Title: "Sales", Legend: true})
+	if err != nil {
+		fmt.Printf("Error calling plot: %v\n", err)
+	} else {
+		fmt.Printf("plot(title=\"Sales\", legend=True) = %v\n", result)
+	}
+
+	// Test a dataclass round trip: a Go struct builds the instance, including
+	// a nested dataclass, and the instance decodes back into the same struct
+	type Address struct {
+		City string `py:"city"`
+		Zip  string `py:"zip"`
+	}
+	type Person struct {
+		Name    string   `py:"name"`
+		Age     int      `py:"age"`
+		Address Address  `py:"address"`
+		Tags    []string `py:"tags"`
+	}
+	dataclassCode := `
+from dataclasses import dataclass, field
+
+@dataclass
+class Address:
+    city: str
+    zip: str
+
+@dataclass
+class Person:
+    name: str
+    age: int
+    address: Address
+    tags: list = field(default_factory=list)
+
+    def __post_init__(self):
+        if isinstance(self.address, dict):
+            self.address = Address(**self.address)
+`
+	if err := py.RunString(dataclassCode); err != nil {
+		fmt.Printf("Error defining dataclasses: %v\n", err)
+	} else {
+		person := Person{Name: "Ada", Age: 36, Address: Address{City: "London", Zip: "W1"}, Tags: []string{"math"}}
+		h, err := py.CallDataclass("__main__", "Person", person)
+		if err != nil {
+			fmt.Printf("Error calling CallDataclass: %v\n", err)
+		} else {
+			nested, nestedErr := py.EvalIn(h, "type(address).__name__")
+			var decoded Person
+			decodeErr := py.UnmarshalDataclass(h, &decoded)
+			h.Close()
+			fmt.Printf("CallDataclass round trip: %+v, equal: %v, nested %v (errs: %v %v)\n",
+				decoded, reflect.DeepEqual(decoded, person), nested, nestedErr, decodeErr)
+		}
+
+		var pyErr *gopython.PyError
+		_, err = py.CallDataclass("__main__", "Person", map[string]interface{}{"name": "Ada", "address": map[string]interface{}{"city": "London", "zip": "W1"}})
+		missingField := errors.As(err, &pyErr) && pyErr.Type == "TypeError"
+		_, err = py.CallDataclass("__main__", "Person", map[string]interface{}{"name": "Ada", "age": 36, "address": nil, "nickname": "A"})
+		extraField := errors.As(err, &pyErr) && pyErr.Type == "TypeError"
+		fmt.Printf("CallDataclass rejects a missing field: %v, an extra field: %v\n", missingField, extraField)
+	}
+
+	// Test keyword arguments arriving in insertion order
+	ordered := gopython.OrderedKwargs{}
+	for _, key := range []string{"zeta", "alpha", "mid", "beta", "omega"} {
+		ordered = append(ordered, gopython.KeyValue{Key: key, Value: len(key)})
+	}
+	result, err = py.CallFunctionOrderedKwargs("__main__", "kwarg_order", nil, ordered)
+	if err != nil {
+		fmt.Printf("Error calling kwarg_order: %v\n", err)
+	} else {
+		fmt.Printf("kwargs order preserved: %v (%v)\n", fmt.Sprint(result) == "[zeta alpha mid beta omega]", result)
+	}
+
+	// Test bools and bool-like scalars in a list staying distinct from ints
+	result, err = py.CallFunction("__main__", "mixed_bools")
+	if err != nil {
+		fmt.Printf("Error calling mixed_bools: %v\n", err)
+	} else {
+		fmt.Printf("mixed_bools() = %#v (bools converted: %v)\n", result,
+			reflect.DeepEqual(result, []interface{}{true, false, true, false, int64(1), int64(0)}))
+	}
+
+	// Test binding freshly defined functions as Go closures
+	funcs, err := py.DefineAndBind(`
+def cube(x):
+    return x ** 3
+
+def shout(text):
+    return text.upper() + "!"
+`)
+	if err != nil {
+		fmt.Printf("Error in DefineAndBind: %v\n", err)
+	} else {
+		cubed, err1 := funcs["cube"](3)
+		shouted, err2 := funcs["shout"]("hi")
+		fmt.Printf("DefineAndBind bound %d functions: cube(3) = %v (%v), shout(\"hi\") = %v (%v)\n", len(funcs), cubed, err1, shouted, err2)
+
+		// Binding cube again releases the first definition
+		if rebound, err := py.DefineAndBind("def cube(x):\n    return x * x * x"); err == nil {
+			_, staleErr := funcs["cube"](3)
+			cubed, err := rebound["cube"](4)
+			fmt.Printf("Rebound cube(4) = %v (%v), earlier closure fails: %v\n", cubed, err, staleErr)
+		}
+	}
+
+	// Test warming up modules ahead of their first use, including one whose
+	// warm-up function is called
+	beforeWarmUp := py.ModuleSnapshot()
+	timings, err := py.WarmUpTimings([]string{"fractions", "secrets:token_hex"})
+	afterWarmUp := py.ModuleSnapshot()
+	warmUpErr := py.WarmUp([]string{"json", "no_such_module_to_warm", "json:no_such_warm_up"})
+	fmt.Printf("WarmUp imported fractions %v and secrets %v (before: %v %v), timed %d modules (err: %v), missing module and function reported: %v\n",
+		afterWarmUp["fractions"], afterWarmUp["secrets"], beforeWarmUp["fractions"], beforeWarmUp["secrets"], len(timings), err,
+		warmUpErr != nil && strings.Contains(warmUpErr.Error(), "no_such_module_to_warm") && strings.Contains(warmUpErr.Error(), "no_such_warm_up"))
+
+	// Test reading structured details from a Python exception
+	_, err = py.CallFunction("os", "stat", "/nonexistent/gopython-missing")
+	var pyErr *gopython.PyError
+	if errors.As(err, &pyErr) {
+		fmt.Printf("os.stat raised %s: errno=%v filename=%v args=%v\n", pyErr.Type, pyErr.Attrs["errno"], pyErr.Attrs["filename"], pyErr.Args)
+	} else {
+		fmt.Printf("Expected a *PyError from os.stat, got %v\n", err)
+	}
+	_, err = py.CallFunction("operator", "getitem", map[string]interface{}{}, "missing")
+	if errors.As(err, &pyErr) {
+		fmt.Printf("getitem raised %s with key %v\n", pyErr.Type, pyErr.Attrs["key"])
+	}
+
+	// Test telling a missing module apart from a missing function
+	_, err = py.CallFunction("gopython_no_such_module", "run")
+	fmt.Printf("missing module: ErrModuleNotFound=%v ErrFunctionNotFound=%v (%v)\n",
+		errors.Is(err, gopython.ErrModuleNotFound), errors.Is(err, gopython.ErrFunctionNotFound), err)
+	_, err = py.CallFunction("json", "no_such_function")
+	fmt.Printf("missing function: ErrModuleNotFound=%v ErrFunctionNotFound=%v (%v)\n",
+		errors.Is(err, gopython.ErrModuleNotFound), errors.Is(err, gopython.ErrFunctionNotFound), err)
+	if dir, err := os.MkdirTemp("", "gopython-deps"); err == nil {
+		defer os.RemoveAll(dir)
+		os.WriteFile(filepath.Join(dir, "gopython_broken_dep.py"), []byte("import gopython_no_such_dependency\n"), 0o644)
+		py.AddSysPath(dir)
+		_, err = py.CallFunction("gopython_broken_dep", "run")
+		fmt.Printf("missing dependency: ErrModuleNotFound=%v (%v)\n", errors.Is(err, gopython.ErrModuleNotFound), err)
+		py.RemoveSysPath(dir)
+	}
+	_, err = py.CallFunction("xml.gopython_no_such_submodule", "run")
+	fmt.Printf("missing submodule: ErrModuleNotFound=%v (%v)\n", errors.Is(err, gopython.ErrModuleNotFound), err)
+
+	// Test reading module constants without calling them
+	pi, err1 := py.GetModuleAttr("math", "pi")
+	maxsize, err2 := py.GetModuleAttr("sys", "maxsize")
+	fmt.Printf("math.pi = %v (%v), sys.maxsize = %v (%v), matches Go: %v\n", pi, err1, maxsize, err2,
+		pi == math.Pi && maxsize == int64(math.MaxInt64))
+	_, err = py.GetModuleAttr("math", "no_such_constant")
+	fmt.Printf("missing module attribute rejected: %v\n", errors.As(err, &pyErr) && pyErr.Type == "AttributeError")
+
+	// Test capturing output, including when the code raises part way through
+	var streamed strings.Builder
+	if err := py.SetStdout(&streamed); err != nil {
+		fmt.Printf("Error redirecting stdout: %v\n", err)
+	}
+	stdout, stderr, err := py.RunStringCaptured("import sys\nprint('captured')\nprint('warning', file=sys.stderr)")
+	fmt.Printf("RunStringCaptured stdout=%q stderr=%q err=%v\n", stdout, stderr, err)
+	stdout, _, err = py.RunStringCaptured("print('before')\nraise ValueError('mid-code')\nprint('after')")
+	fmt.Printf("RunStringCaptured with exception: stdout=%q, raised ValueError: %v\n", stdout, errors.As(err, &pyErr) && pyErr.Type == "ValueError")
+	py.RunString("print('streamed again')")
+	fmt.Printf("SetStdout writer restored after capture: %v (%q)\n", streamed.String() == "streamed again\n", streamed.String())
+	py.SetStdout(nil)
+	py.RunStringCaptured("raise RuntimeError('no output')")
+	py.RunString("import sys\nstreams_restored = sys.stdout is sys.__stdout__ and sys.stderr is sys.__stderr__")
+	restored, err := py.GetGlobal("streams_restored")
+	fmt.Printf("original streams restored: %v (%v)\n", restored, err)
+
+	// Test that code with an embedded NUL byte is rejected instead of truncated
+	err = py.RunString("nul_ran = 'before'\nnul_ran = 'a\x00b'\nnul_ran = 'after'")
+	_, defined := py.GetGlobal("nul_ran")
+	fmt.Printf("RunString with NUL byte rejected: %v, nothing executed: %v (%v)\n", err != nil, defined != nil, err)
+	var nulSyntax *gopython.SyntaxError
+	if errors.As(py.CheckSyntax("x = 1\ny = '\x00'"), &nulSyntax) {
+		fmt.Printf("CheckSyntax reports NUL byte at line %d, offset %d\n", nulSyntax.Line, nulSyntax.Offset)
+	}
+
+	// Test setting inputs as globals and reading outputs back
+	if err := py.SetGlobal("radius", 2.0); err != nil {
+		fmt.Printf("Error setting global: %v\n", err)
+	} else if err := py.RunString("area = round(3.14159 * radius ** 2, 2)"); err != nil {
+		fmt.Printf("Error computing area: %v\n", err)
+	} else {
+		area, err := py.GetGlobal("area")
+		fmt.Printf("GetGlobal(\"area\") = %v (err: %v)\n", area, err)
+		_, err = py.GetGlobal("undefined_global")
+		fmt.Printf("GetGlobal(\"undefined_global\") error: %v\n", err)
+	}
+
+	// Test a list of tuples returned by enumerate
+	result, err = py.CallFunction("__main__", "enumerate_letters", []interface{}{"a", "b"})
+	if err != nil {
+		fmt.Printf("Error calling enumerate_letters: %v\n", err)
+	} else {
+		pairs := result.([]interface{})
+		first, second := pairs[0].([]interface{}), pairs[1].([]interface{})
+		ok := len(pairs) == 2 && first[0] == int64(0) && first[1] == "a" && second[0] == int64(1) && second[1] == "b"
+		fmt.Printf("enumerate_letters([a b]) = %v (as expected: %v)\n", pairs, ok)
+	}
+
+	// Test seeding several globals at once
+	err = py.SetGlobals("__main__", map[string]interface{}{
+		"cfg_name":    "resnet",
+		"cfg_batch":   32,
+		"cfg_enabled": true,
+	})
+	if err != nil {
+		fmt.Printf("Error setting globals: %v\n", err)
+	} else if err := py.RunString("cfg_summary = f'{cfg_name}:{cfg_batch}:{cfg_enabled}'"); err != nil {
+		fmt.Printf("Error reading globals in Python: %v\n", err)
+	} else {
+		summary, err := py.GetGlobal("cfg_summary")
+		fmt.Printf("SetGlobals seen by Python as %v (err: %v)\n", summary, err)
+	}
+
+	// Test evaluating expressions against an instance handle
+	if point, err := py.NewInstance("__main__", "Point", 3, 4); err != nil {
+		fmt.Printf("Error creating Point: %v\n", err)
+	} else {
+		sum, err1 := py.EvalIn(point, "obj.x + obj.y")
+		norm, err2 := py.EvalIn(point, "round((x ** 2 + y ** 2) ** 0.5, 1)")
+		_, badErr := py.EvalIn(point, "obj.z")
+		fmt.Printf("EvalIn obj.x + obj.y = %v, norm = %v (errs: %v %v), missing attribute rejected: %v\n",
+			sum, norm, err1, err2, badErr != nil)
+		point.Close()
+	}
+
+	// Test calling function objects held as handles: a handler returned by a
+	// factory, a functools.partial and a non-callable instance
+	if err := py.RunString("def make_scaler(factor):\n    def scale(value, offset=0):\n        return value * factor + offset\n    return scale"); err != nil {
+		fmt.Printf("Error defining make_scaler: %v\n", err)
+	} else if scaler, err := py.CallFunctionRaw("__main__", "make_scaler", 3); err != nil {
+		fmt.Printf("Error calling make_scaler: %v\n", err)
+	} else {
+		scaled, err1 := py.CallCallable(scaler, 7)
+		partial, _ := py.NewInstance("functools", "partial", scaler, 10)
+		fromPartial, err2 := py.CallCallable(partial, 5)
+		point, _ := py.NewInstance("__main__", "Point", 1, 2)
+		_, notCallable := py.CallCallable(point)
+		fmt.Printf("CallCallable scale(7) = %v, partial(scale, 10)(5) = %v (errs: %v %v), non-callable rejected: %v\n",
+			scaled, fromPartial, err1, err2, notCallable)
+		gopython.CloseAll([]interface{}{scaler, partial, point})
+	}
+
+	// Test best-effort conversion embedding handles for unconvertible leaves
+	if err := py.RunString("def mixed_leaves():\n    return {'count': 3, 'nothing': None, 'point': Point(1, 2), 'more': [Point(5, 6), 'text']}"); err != nil {
+		fmt.Printf("Error defining mixed_leaves: %v\n", err)
+	} else if raw, err := py.CallFunctionRaw("__main__", "mixed_leaves"); err != nil {
+		fmt.Printf("Error calling mixed_leaves: %v\n", err)
+	} else {
+		_, strictErr := py.CallFunction("__main__", "mixed_leaves")
+		value, handles, err := py.ConvertDeepOrHandle(raw)
+		raw.Close()
+		if err != nil {
+			fmt.Printf("Error in ConvertDeepOrHandle: %v\n", err)
+		} else {
+			mixed := value.(map[string]interface{})
+			point, isHandle := gopython.AsHandle(mixed["point"])
+			_, countIsHandle := gopython.AsHandle(mixed["count"])
+			x, _ := py.GetAttr(point, "x")
+			fmt.Printf("ConvertDeepOrHandle count=%v nothing=%v point.x=%v more[1]=%v handles=%d (strict conversion failed: %v)\n",
+				mixed["count"], mixed["nothing"], x, mixed["more"].([]interface{})[1], len(handles), strictErr != nil)
+			fmt.Printf("AsHandle point=%v count=%v\n", isHandle, countIsHandle)
+			gopython.CloseAll(value)
+			closed := 0
+			for _, h := range handles {
+				if _, err := py.GetAttr(h, "x"); err != nil {
+					closed++
+				}
+			}
+			fmt.Printf("CloseAll closed %d of %d embedded handles\n", closed, len(handles))
+		}
+	}
+
+	// Test converting unsupported leaves to UnknownValue with their repr
+	py.SetReprFallback(true)
+	if result, err := py.CallFunction("__main__", "mixed_leaves"); err != nil {
+		fmt.Printf("Error converting mixed_leaves with repr fallback: %v\n", err)
+	} else {
+		mixed := result.(map[string]interface{})
+		point, ok := mixed["point"].(gopython.UnknownValue)
+		fmt.Printf("SetReprFallback count=%v point is UnknownValue %v (type %s, repr %v), more[0] %T\n",
+			mixed["count"], ok, point.TypeName, strings.HasPrefix(point.Repr, "<__main__.Point object at"), mixed["more"].([]interface{})[0])
+	}
+	py.SetReprFallback(false)
+
+	// Test skipping one unconvertible list item instead of failing the list
+	if err := py.RunString("def one_odd_item():\n    return [1, 'two', Point(3, 4), {'nested': [Point(5, 6), 7]}]"); err != nil {
+		fmt.Printf("Error defining one_odd_item: %v\n", err)
+	} else {
+		_, strictErr := py.CallFunction("__main__", "one_odd_item")
+		py.SetSkipUnconvertible(gopython.SkipUnconvertible{Enabled: true})
+		quiet, quietErr := py.CallFunction("__main__", "one_odd_item")
+		py.SetSkipUnconvertible(gopython.SkipUnconvertible{Enabled: true, AsHandle: true, CollectErrors: true})
+		partial, err := py.CallFunction("__main__", "one_odd_item")
+		py.SetSkipUnconvertible(gopython.SkipUnconvertible{})
+
+		var skipped gopython.ConversionErrors
+		collected := errors.As(err, &skipped)
+		items := partial.([]interface{})
+		point, isHandle := gopython.AsHandle(items[2])
+		x, _ := py.GetAttr(point, "x")
+		fmt.Printf("SkipUnconvertible strict failed %v, nil placeholders %v (err: %v)\n", strictErr != nil, quiet, quietErr)
+		fmt.Printf("SkipUnconvertible items[:2]=%v handle %v x=%v, %d errors collected %v: %v\n",
+			items[:2], isHandle, x, len(skipped), collected, err)
+		gopython.CloseAll(partial)
+
+		// Partial values reach callers wrapping conversion errors, and a
+		// fatal failure releases the placeholders created before it
+		py.RunString("odd_global = one_odd_item()\nclass Holder:\n    def __init__(self):\n        self.count = 2\n        self.points = [Point(1, 1)]\nimport sys\nshared_point = Point(7, 7)\ntoo_deep = [shared_point, [[[[[1]]]]]]")
+		py.SetSkipUnconvertible(gopython.SkipUnconvertible{Enabled: true, AsHandle: true, CollectErrors: true})
+		global, globalErr := py.GetGlobal("odd_global")
+		holder, _ := py.NewInstance("__main__", "Holder")
+		attrs, varsErr := py.Vars(holder)
+		holder.Close()
+		refCode := "import sys, __main__\nrefs = sys.getrefcount(__main__.shared_point)"
+		refsBefore, _ := py.RunIsolated(refCode)
+		py.SetMaxConversionDepth(4)
+		deep, deepErr := py.GetGlobal("too_deep")
+		py.SetMaxConversionDepth(0)
+		py.SetSkipUnconvertible(gopython.SkipUnconvertible{})
+		refsAfter, _ := py.RunIsolated(refCode)
+		fmt.Printf("Partial GetGlobal %d items (partial: %v), Vars count=%v points kept %v (partial: %v)\n",
+			len(global.([]interface{})), errors.As(globalErr, &skipped), attrs["count"], attrs["points"] != nil, errors.As(varsErr, &skipped))
+		fmt.Printf("Too deep after a placeholder: %v, fails: %v, placeholder released: %v\n",
+			deep, deepErr != nil && strings.Contains(deepErr.Error(), "max depth exceeded"), refsBefore["refs"] == refsAfter["refs"])
+		gopython.CloseAll(global)
+		gopython.CloseAll(attrs)
+	}
+
+	// Test introspecting an annotated function for generated Go wrappers
+	wrapperCode := `
+from typing import Dict, Optional
+
+_missing = object()
+
+def fit(data: list, epochs: int = 10, /, *, lr: float = 0.01, name: Optional[str] = None,
+        marker=_missing, **extra: "Extra") -> Dict[str, float]:
+    """Train on data."""
+`
+	if err := py.RunString(wrapperCode); err != nil {
+		fmt.Printf("Error defining fit: %v\n", err)
+	} else if spec, err := py.GenerateWrapperSpec("__main__", "fit"); err != nil {
+		fmt.Printf("Error in GenerateWrapperSpec: %v\n", err)
+	} else {
+		fmt.Printf("GenerateWrapperSpec %s.%s -> %s, doc %q\n", spec.Module, spec.Function, spec.Returns, spec.Doc)
+		for _, p := range spec.Params {
+			fmt.Printf("  %s %s annotation=%q has default %v: %v (sentinel repr %v)\n",
+				p.Kind, p.Name, p.Annotation, p.HasDefault, p.Default, strings.HasPrefix(p.DefaultRepr, "<object object at"))
+		}
+		_, missingErr := py.GenerateWrapperSpec("__main__", "no_such_function")
+		fmt.Printf("GenerateWrapperSpec of missing function: ErrFunctionNotFound=%v\n", errors.Is(missingErr, gopython.ErrFunctionNotFound))
+	}
+
+	// Test that repeated conversion of a large nested structure leaves every
+	// reference count unchanged
+	refcountCode := `
+import sys
+leaf = "shared-leaf-" + str(id(object()))
+refcount_data = {
+    "rows": [{"id": i, "values": [i * 1.5, leaf, (i, None, b"raw")], "tags": {"k": leaf}} for i in range(10)],
+    "meta": (leaf, [leaf] * 10),
+}
+def refcounts():
+    rows = refcount_data["rows"]
+    return [sys.getrefcount(refcount_data), sys.getrefcount(rows), sys.getrefcount(rows[0]),
+            sys.getrefcount(rows[0]["values"]), sys.getrefcount(leaf)]
+`
+	if err := py.RunString(refcountCode); err != nil {
+		fmt.Printf("Error defining refcount_data: %v\n", err)
+	} else {
+		before, _ := py.CallFunction("__main__", "refcounts")
+		var convertErr error
+		for i := 0; i < 2000 && convertErr == nil; i++ {
+			_, convertErr = py.GetGlobal("refcount_data")
+		}
+		after, _ := py.CallFunction("__main__", "refcounts")
+		fmt.Printf("2000 nested conversions: refcounts unchanged %v (err: %v)\n", reflect.DeepEqual(before, after), convertErr)
+	}
+
+	// Test that values nested past SetMaxConversionDepth fail in both
+	// directions, while values at the limit still convert
+	var nested interface{} = 1
+	for i := 0; i < 6; i++ {
+		nested = []interface{}{nested}
+	}
+	py.RunString("nested_5 = [[[[[1]]]]]\nnested_6 = [nested_5]")
+	py.SetMaxConversionDepth(5)
+	_, toPythonErr := py.CallFunction("builtins", "len", nested)
+	_, toGoErr := py.GetGlobal("nested_6")
+	atLimit, atLimitErr := py.GetGlobal("nested_5")
+	py.SetMaxConversionDepth(0)
+	_, defaultErr := py.GetGlobal("nested_6")
+	fmt.Printf("Depth 6 with limit 5 fails with ErrMaxDepthExceeded: to Python %v, to Go %v\n",
+		errors.Is(toPythonErr, gopython.ErrMaxDepthExceeded), errors.Is(toGoErr, gopython.ErrMaxDepthExceeded))
+	fmt.Printf("Depth 5 with limit 5 converts: %v (err: %v), depth 6 with the default limit: %v\n", atLimit, atLimitErr, defaultErr)
+
+	// Test that a list emptied by an item's own conversion code is reported
+	// instead of reading freed or missing items
+	py.SetNumericFallback(true)
+	shrinkCode := "class Shrinker:\n    __array__ = None\n    def __index__(self):\n        shrinking.clear()\n        return 7\nshrinking = [Shrinker(), Shrinker(), Shrinker()]"
+	if err := py.RunString(shrinkCode); err != nil {
+		fmt.Printf("Error defining Shrinker: %v\n", err)
+	} else {
+		_, err := py.GetGlobal("shrinking")
+		fmt.Printf("List shrunk during conversion reported: %v\n", err)
+	}
+	py.SetNumericFallback(false)
+
+	// Test set membership against a set held as a handle
+	if err := py.RunString("def make_big_set():\n    return set(range(1000)) | {frozenset({'x', 'y'})}"); err != nil {
+		fmt.Printf("Error defining make_big_set: %v\n", err)
+	} else if bigSet, err := py.CallFunctionRaw("__main__", "make_big_set"); err != nil {
+		fmt.Printf("Error creating set: %v\n", err)
+	} else {
+		has999, err1 := py.InSet(bigSet, 999)
+		has1000, err2 := py.InSet(bigSet, 1000)
+		hasPair, err3 := py.InSet(bigSet, gopython.FrozenSetValue{"y", "x"})
+		_, unhashable := py.InSet(bigSet, []interface{}{1})
+		fmt.Printf("InSet 999=%v 1000=%v frozenset=%v (errs: %v %v %v), list rejected: %v\n",
+			has999, has1000, hasPair, err1, err2, err3, unhashable != nil)
+		bigSet.Close()
+	}
+
+	// Test defining an importable module from generated source
+	if err := py.DefineModule("generated_rules", "def score(x):\n    return x * 2\n"); err != nil {
+		fmt.Printf("Error defining module: %v\n", err)
+	} else {
+		first, _ := py.CallFunction("generated_rules", "score", 21)
+		py.DefineModule("generated_rules", "def score(x):\n    return x * 3\n")
+		second, _ := py.CallFunction("generated_rules", "score", 21)
+		badErr := py.DefineModule("generated_rules", "def score(x:\n")
+		kept, _ := py.CallFunction("generated_rules", "score", 21)
+		imported, err := py.RunIsolated("from generated_rules import score\nresult = score(1)")
+		fmt.Printf("DefineModule score(21) = %v, after redefine %v, after syntax error %v (rejected: %v), imported: %v (err: %v)\n",
+			first, second, kept, badErr != nil, imported["result"], err)
+	}
+
+	// Test passing a multi-megabyte string, with multibyte and NUL characters
+	bigText := strings.Repeat("größe ✓ {\"k\": 1}\x00\n", 1<<18)
+	start := time.Now()
+	charCount, err := py.CallFunction("builtins", "len", bigText)
+	passTime := time.Since(start)
+	if err != nil {
+		fmt.Printf("Error passing large string: %v\n", err)
+	} else {
+		echoed, err := py.CallFunction("builtins", "str", bigText)
+		fmt.Printf("%.1f MB string passed in %v: length matches %v, round trip intact %v (err: %v)\n",
+			float64(len(bigText))/(1<<20), passTime.Round(time.Millisecond),
+			charCount == int64(utf8.RuneCountInString(bigText)), echoed == bigText, err)
+	}
+
+	// Test Python summing values pulled from a Go channel as they are sent
+	values := make(chan interface{})
+	go func() {
+		for i := 1; i <= 100; i++ {
+			values <- i
+		}
+		close(values)
+	}()
+	if it, err := py.WrapChannel(values); err != nil {
+		fmt.Printf("Error wrapping channel: %v\n", err)
+	} else {
+		total, err := py.CallFunction("builtins", "sum", it)
+		rest, restErr := py.CallFunction("builtins", "list", it)
+		it.Close()
+		fmt.Printf("WrapChannel sum(1..100) = %v (err: %v), exhausted afterwards: %v (err: %v)\n", total, err, rest, restErr)
+	}
+
+	// Test NumPy array conversion. An array.array subclass named ndarray stands
+	// in for NumPy when it isn't installed: both expose the buffer protocol
+	// and tolist().
+	ndarrayCode := `
+import array
+try:
+    import numpy
+    def make_ndarray(kind, n):
+        return numpy.arange(n, dtype='float64' if kind == 'd' else 'int64')
+except ImportError:
+    class ndarray(array.array):
+        pass
+    def make_ndarray(kind, n):
+        return ndarray(kind, [i / 2 for i in range(n)] if kind == 'd' else range(n))
+`
+	if err := py.RunString(ndarrayCode); err != nil {
+		fmt.Printf("Error defining make_ndarray: %v\n", err)
+	} else {
+		ints, err1 := py.CallFunction("__main__", "make_ndarray", "q", 5)
+		floats, err2 := py.CallFunction("__main__", "make_ndarray", "d", 5)
+		fmt.Printf("ndarray conversion: %T %v, %T %v (errs: %v %v)\n", ints, ints, floats, floats, err1, err2)
+
+		const elements, listElements = 1_000_000, 20_000
+		if big, err := py.CallFunctionRaw("__main__", "make_ndarray", "d", elements); err != nil {
+			fmt.Printf("Error creating large ndarray: %v\n", err)
+		} else {
+			start := time.Now()
+			values, err := py.EvalIn(big, "obj")
+			floats, _ := values.([]float64)
+			bufferTime := time.Since(start)
+			start = time.Now()
+			py.EvalIn(big, fmt.Sprintf("obj.tolist()[:%d]", listElements))
+			listTime := time.Since(start) * elements / listElements
+			fmt.Printf("1M-element ndarray converted to %d floats via the buffer protocol (err: %v), over 100x faster than per-element conversion: %v\n",
+				len(floats), err, listTime > 100*bufferTime)
+			big.Close()
+		}
+	}
+
+	// Test surrogate-escaped string (non-UTF-8 filename bytes)
+	result, err = py.CallFunction("os", "fsdecode", []byte("caf\xe9.txt"))
+	if err != nil {
+		fmt.Printf("Error calling os.fsdecode: %v\n", err)
+	} else {
+		fmt.Printf("os.fsdecode(b\"caf\\xe9.txt\") = %q (raw bytes preserved: %v)\n", result, result == "caf\xe9.txt")
+	}
+
+	// Test opening a relative file under a temporary working directory
+	if dir, err := os.MkdirTemp("", "gopython-wd"); err != nil {
+		fmt.Printf("Error creating temp dir: %v\n", err)
+	} else {
+		defer os.RemoveAll(dir)
+		os.WriteFile(filepath.Join(dir, "greeting.txt"), []byte("hello from a relative path"), 0644)
+		var greeting interface{}
+		err = py.WithWorkingDir(dir, func() error {
+			var err error
+			greeting, err = py.CallFunction("__main__", "read_relative", "greeting.txt")
+			return err
+		})
+		if err != nil {
+			fmt.Printf("Error reading relative file: %v\n", err)
+		} else {
+			fmt.Printf("WithWorkingDir read %q\n", greeting)
+		}
+	}
+
+	// Test collecting the first items of an infinite iterator
+	if counter, err := py.CallFunctionRaw("itertools", "count"); err != nil {
+		fmt.Printf("Error creating itertools.count: %v\n", err)
+	} else {
+		items, err := py.CollectIterator(counter, 5)
+		counter.Close()
+		expected := []interface{}{int64(0), int64(1), int64(2), int64(3), int64(4)}
+		fmt.Printf("CollectIterator(itertools.count(), 5) = %v, as expected: %v (err: %v)\n", items, reflect.DeepEqual(items, expected) && err == nil, err)
+	}
+
+	// Test that invalid handles are rejected with errors instead of crashing
+	py.SetRecoverPanics(true)
+	for label, h := range map[string]*gopython.PyHandle{"nil": nil, "zero": {}} {
+		var failures []string
+		check := func(method string, err error) {
+			if err == nil {
+				failures = append(failures, method)
+			}
+		}
+		_, err := py.CallMethod(h, "method")
+		check("CallMethod", err)
+		_, err = py.CallCallable(h)
+		check("CallCallable", err)
+		_, err = py.GetAttr(h, "attr")
+		check("GetAttr", err)
+		check("SetAttr", py.SetAttr(h, "attr", 1))
+		_, err = py.Vars(h)
+		check("Vars", err)
+		_, _, err = py.Iterate(h)
+		check("Iterate", err)
+		_, err = py.CollectIterator(h, 0)
+		check("CollectIterator", err)
+		_, err = py.InSet(h, 1)
+		check("InSet", err)
+		_, err = py.EvalIn(h, "obj")
+		check("EvalIn", err)
+		var target struct{}
+		check("UnmarshalDataclass", py.UnmarshalDataclass(h, &target))
+		if err := h.Close(); err != nil {
+			failures = append(failures, "Close")
+		}
+		fmt.Printf("%s handle rejected by every method: %v\n", label, len(failures) == 0)
+	}
+	var prepared *gopython.PreparedCall
+	if _, err := prepared.Call(); err != nil {
+		fmt.Printf("nil PreparedCall rejected: %v\n", err)
+	}
+
+	// Test batching many calls under one lock, with failures isolated to
+	// their own result
+	const records = 10_000
+	calls := make([]gopython.Call, 0, records+2)
+	for i := 0; i < records; i++ {
+		calls = append(calls, gopython.Call{Module: "__main__", Function: "add_numbers", Args: []interface{}{i, 1}})
+	}
+	calls = append(calls,
+		gopython.Call{Module: "__main__", Function: "add_numbers", Args: []interface{}{1}},
+		gopython.Call{Module: "__main__", Function: "no_such_function"})
+	start = time.Now()
+	results, err := py.CallBatch(calls)
+	batchTime := time.Since(start)
+	if err != nil {
+		fmt.Printf("Error running CallBatch: %v\n", err)
+	} else {
+		start = time.Now()
+		for i := 0; i < records; i++ {
+			py.CallFunction("__main__", "add_numbers", i, 1)
+		}
+		loopTime := time.Since(start)
+		succeeded := 0
+		for i, result := range results[:records] {
+			if result.Err == nil && result.Value == int64(i+1) {
+				succeeded++
+			}
+		}
+		fmt.Printf("CallBatch %d/%d calls succeeded, bad arguments isolated: %v, missing function isolated: %v\n",
+			succeeded, records, results[records].Err != nil, errors.Is(results[records+1].Err, gopython.ErrFunctionNotFound))
+		fmt.Printf("CallBatch took %v for %d calls, CallFunction loop %v\n",
+			batchTime.Round(time.Millisecond), records, loopTime.Round(time.Millisecond))
+	}
+
+	// Test importing a module from a directory added to sys.path
+	if dir, err := os.MkdirTemp("", "gopython-path's"); err != nil {
+		fmt.Printf("Error creating temp dir: %v\n", err)
+	} else {
+		defer os.RemoveAll(dir)
+		os.WriteFile(filepath.Join(dir, "sidecar_module.py"), []byte("def answer():\n    return 42\n"), 0644)
+		if err := py.AddSysPath(dir); err != nil {
+			fmt.Printf("Error adding sys.path entry: %v\n", err)
+		} else {
+			result, err := py.CallFunction("sidecar_module", "answer")
+			fmt.Printf("sidecar_module.answer() = %v, err = %v\n", result, err)
+			os.WriteFile(filepath.Join(dir, "sidecar_module.py"), []byte("def answer():\n    return 'edited'\n"), 0644)
+			reloadErr := py.ReloadModule("sidecar_module")
+			result, err = py.CallFunction("sidecar_module", "answer")
+			fmt.Printf("after ReloadModule (err: %v): answer() = %v, err = %v\n", reloadErr, result, err)
+			fmt.Printf("ReloadModule of a module never imported: %v\n", py.ReloadModule("never_imported_module"))
+
+			// Test unloading the modules a plugin imported after a snapshot
+			os.MkdirAll(filepath.Join(dir, "plugin_pkg"), 0o755)
+			os.WriteFile(filepath.Join(dir, "plugin_pkg", "__init__.py"), nil, 0o644)
+			os.WriteFile(filepath.Join(dir, "plugin_pkg", "tools.py"), []byte("import colorsys\ndef version():\n    return 1\n"), 0o644)
+			before := py.ModuleSnapshot()
+			version, err := py.CallFunction("plugin_pkg.tools", "version")
+			loaded := py.ModuleSnapshot()
+			unloadErr := py.UnloadModulesSince(before)
+			after := py.ModuleSnapshot()
+			os.WriteFile(filepath.Join(dir, "plugin_pkg", "tools.py"), []byte("def version():\n    return 2\n"), 0o644)
+			reimported, reimportErr := py.CallFunction("plugin_pkg.tools", "version")
+			fmt.Printf("UnloadModulesSince: version %v (err: %v), loaded %v %v %v, unloaded (err: %v) %v %v %v, kept sidecar_module %v, sys %v, reimported version %v (err: %v), empty snapshot rejected: %v\n",
+				version, err, loaded["plugin_pkg"], loaded["plugin_pkg.tools"], loaded["colorsys"] && !before["colorsys"],
+				unloadErr, !after["plugin_pkg"], !after["plugin_pkg.tools"], !after["colorsys"],
+				after["sidecar_module"], after["sys"], reimported, reimportErr, py.UnloadModulesSince(nil) != nil)
+
+			fmt.Printf("RemoveSysPath: %v, second RemoveSysPath fails: %v\n", py.RemoveSysPath(dir), py.RemoveSysPath(dir) != nil)
+		}
+	}
+
+	// Test saving, raising and restoring the recursion limit
+	if defaultLimit, err := py.RecursionLimit(); err != nil {
+		fmt.Printf("Error getting recursion limit: %v\n", err)
+	} else {
+		setErr := py.SetRecursionLimit(defaultLimit + 500)
+		raised, _ := py.RecursionLimit()
+		badErr := py.SetRecursionLimit(0)
+		py.SetRecursionLimit(defaultLimit)
+		restored, _ := py.RecursionLimit()
+		fmt.Printf("RecursionLimit default %d, raised to %d (err: %v), zero rejected: %v, restored %v\n",
+			defaultLimit, raised, setErr, badErr != nil, restored == defaultLimit)
+	}
+
 	fmt.Println("\nPhase 3 implementation complete!")
 
 	// Test limitations and compatibility
@@ -146,4 +1072,22 @@ def greet(name):
 	if err := py.RunFile("examples/basic/test_limitations.py"); err != nil {
 		fmt.Printf("Limitations test error: %v\n", err)
 	}
+
+	// Test that the runtime rejects calls cleanly once closed
+	fmt.Printf("Ping before Close: %v\n", py.Ping())
+
+	fmt.Println("\n=== Testing Close ===")
+	if err := py.Close(); err != nil {
+		fmt.Printf("Error closing runtime: %v\n", err)
+	}
+	_, err = py.CallFunction("math", "sqrt", 4.0)
+	fmt.Printf("CallFunction after Close returns ErrNotInitialized: %v\n", errors.Is(err, gopython.ErrNotInitialized))
+	fmt.Printf("RunString after Close returns ErrNotInitialized: %v\n", errors.Is(py.RunString("x = 1"), gopython.ErrNotInitialized))
+	fmt.Printf("Ping after Close returns ErrNotInitialized: %v\n", errors.Is(py.Ping(), gopython.ErrNotInitialized))
+	fmt.Printf("Settings after Close return ErrClosed: %v %v %v %v\n", errors.Is(py.SetFloatRepr(true), gopython.ErrClosed),
+		errors.Is(py.SetSkipUnconvertible(gopython.SkipUnconvertible{Enabled: true}), gopython.ErrClosed), errors.Is(py.SetRecoverPanics(true), gopython.ErrClosed),
+		errors.Is(py.SetMaxConversionDepth(10), gopython.ErrClosed))
+	fmt.Printf("Initialize after Close returns ErrClosed: %v\n", errors.Is(py.Initialize(), gopython.ErrClosed))
+	fmt.Printf("Second Close is a no-op: %v\n", py.Close() == nil)
+	fmt.Printf("Finalize after Close is a no-op: %v\n", py.Finalize() == nil)
 }
\ No newline at end of file
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -59,6 +61,11 @@ def worker_info(worker_id):
         "time": time.time()
     }
 
+def runaway():
+    """Never returns on its own"""
+    while True:
+        pass
+
 def concurrent_counter(start, increment):
     """Test concurrent operations"""
     result = start
@@ -199,5 +206,113 @@ def concurrent_counter(start, increment):
 		fmt.Printf("⚠️  Some calls failed - this may indicate thread safety issues or other problems.\n")
 	}
 
+	// Test 4: Go callback that calls back into Python
+	fmt.Println("\nTest 4: Re-entrant callback (callback calls CallFunction)")
+	err = py.RegisterCallback("square_root", func(args []interface{}) (interface{}, error) {
+		return py.CallFunction("math", "sqrt", args...)
+	})
+	if err != nil {
+		fmt.Printf("Error registering callback: %v\n", err)
+	} else {
+		done := make(chan error, 1)
+		go func() {
+			done <- py.RunString("import gocallbacks\nassert gocallbacks.square_root(81.0) == 9.0")
+		}()
+		select {
+		case err := <-done:
+			if err != nil {
+				fmt.Printf("Re-entrant callback failed: %v\n", err)
+			} else {
+				fmt.Println("✅ Re-entrant callback completed without deadlock")
+			}
+		case <-time.After(10 * time.Second):
+			fmt.Println("❌ Re-entrant callback deadlocked")
+		}
+	}
+
+	// Test 5: Channel-based worker
+	fmt.Println("\nTest 5: Worker processing tasks sent over a channel")
+	tasks, workerResults := py.StartWorker()
+	go func() {
+		tasks <- gopython.Task{Code: "worker_total = 0"}
+		for i := 1; i <= 5; i++ {
+			tasks <- gopython.Task{Module: "math", Function: "pow", Args: []interface{}{float64(i), 2.0}}
+		}
+		close(tasks)
+	}()
+	var squares []interface{}
+	workerFailed := false
+	for result := range workerResults {
+		if result.Err != nil {
+			fmt.Printf("Task %+v failed: %v\n", result.Task, result.Err)
+			workerFailed = true
+			continue
+		}
+		if result.Task.Function != "" {
+			squares = append(squares, result.Value)
+		}
+	}
+	if !workerFailed {
+		fmt.Printf("✅ Worker results in order: %v\n", squares)
+	}
+
+	// Test 6: Request-scoped timeout aborts a runaway call
+	fmt.Println("\nTest 6: Context timeout interrupts a runaway call")
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	waiting := make(chan time.Duration, 1)
+	go func() {
+		// Queued behind the runaway call until it is interrupted
+		time.Sleep(50 * time.Millisecond)
+		start := time.Now()
+		py.CallFunction("__main__", "factorial", 5)
+		waiting <- time.Since(start)
+	}()
+	callStart := time.Now()
+	_, err = py.CallFunctionContext(ctx, "__main__", "runaway")
+	cancel()
+	if errors.Is(err, context.DeadlineExceeded) {
+		fmt.Printf("✅ Runaway call aborted after %v, queued call waited %v\n",
+			time.Since(callStart).Round(10*time.Millisecond), (<-waiting).Round(10*time.Millisecond))
+	} else {
+		fmt.Printf("❌ Expected deadline error, got: %v\n", err)
+	}
+
+	// Test 7: Shutdown interrupts in-flight calls from every goroutine
+	fmt.Println("\nTest 7: InterruptAll aborts busy loops running in other goroutines")
+	busy := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := py.CallFunction("__main__", "runaway")
+			busy <- err
+		}()
+	}
+	time.Sleep(200 * time.Millisecond)
+	shutdownStart := time.Now()
+	aborted := 0
+	for aborted < 2 {
+		// The second call only starts once the first is interrupted
+		if _, err := py.InterruptAll(); err != nil {
+			fmt.Printf("❌ InterruptAll failed: %v\n", err)
+			break
+		}
+		select {
+		case err := <-busy:
+			var pyErr *gopython.PyError
+			if !errors.As(err, &pyErr) || pyErr.Type != "KeyboardInterrupt" {
+				fmt.Printf("❌ Expected KeyboardInterrupt, got: %v\n", err)
+			}
+			aborted++
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+	if aborted == 2 {
+		fmt.Printf("✅ Both busy loops aborted within %v\n", time.Since(shutdownStart).Round(10*time.Millisecond))
+	}
+	if result, err := py.CallFunction("__main__", "factorial", 5); err != nil || result != int64(120) {
+		fmt.Printf("❌ Interpreter unusable after InterruptAll: %v, %v\n", result, err)
+	} else {
+		fmt.Println("✅ Interpreter still usable after InterruptAll")
+	}
+
 	fmt.Println("\n=== Concurrency Safety Test Complete ===")
 }
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/develerltd/gopython310"
+)
+
+// Checks how DiscoverLibPython locates the library: the search order, the
+// GOPYTHON_LIBPYTHON override and the error when nothing is found
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal("Usage: go run examples/discover/main.go <path-to-libpython3.10.so>")
+	}
+
+	libpythonPath := os.Args[1]
+	os.Unsetenv(gopython.LibPythonEnv)
+
+	// Test 1: The search returns the first existing candidate, and the path
+	// it returns loads
+	fmt.Println("=== Test 1: Search order ===")
+	discovered, err := checkSearch()
+	if err != nil {
+		log.Fatalf("Search: %v", err)
+	}
+	if discovered == "" {
+		fmt.Println("No candidate exists on this machine, not found error returned")
+	} else {
+		if err := checkLoads(""); err != nil {
+			log.Fatalf("Discovered library %s: %v", discovered, err)
+		}
+		fmt.Printf("Discovered and loaded %s\n", discovered)
+	}
+
+	// Test 2: Without python3.10-config on PATH only the fixed locations are
+	// searched, so on most machines nothing is found
+	fmt.Println("\n=== Test 2: Search without python3.10-config ===")
+	path := os.Getenv("PATH")
+	os.Setenv("PATH", "")
+	discovered, err = checkSearch()
+	os.Setenv("PATH", path)
+	if err != nil {
+		log.Fatalf("Search without PATH: %v", err)
+	}
+	if discovered == "" {
+		fmt.Println("Not found error returned, as expected")
+	} else {
+		fmt.Printf("Found %s in a fixed location, as expected\n", discovered)
+	}
+
+	// Test 3: The override wins over the search and loads
+	fmt.Println("\n=== Test 3: GOPYTHON_LIBPYTHON override ===")
+	os.Setenv(gopython.LibPythonEnv, libpythonPath)
+	discovered, err = gopython.DiscoverLibPython()
+	if err != nil || discovered != libpythonPath {
+		log.Fatalf("DiscoverLibPython with override = %q, %v, want %q", discovered, err, libpythonPath)
+	}
+	if err := checkLoads(""); err != nil {
+		log.Fatalf("Override %s: %v", libpythonPath, err)
+	}
+	fmt.Printf("Override returned and loaded %s\n", discovered)
+
+	// Test 4: An override that doesn't point to a library is rejected
+	// instead of falling back to the search
+	fmt.Println("\n=== Test 4: Bogus override ===")
+	bogus := "/nonexistent/libpython3.10.so"
+	os.Setenv(gopython.LibPythonEnv, bogus)
+	discovered, err = gopython.DiscoverLibPython()
+	if err == nil || errors.Is(err, gopython.ErrLibPythonNotFound) || !strings.Contains(err.Error(), gopython.LibPythonEnv) {
+		log.Fatalf("DiscoverLibPython with bogus override = %q, %v, want an error naming %s", discovered, err, gopython.LibPythonEnv)
+	}
+	if _, err := gopython.NewPureGoPython(""); err == nil {
+		log.Fatal("NewPureGoPython(\"\") succeeded with a bogus override")
+	}
+	fmt.Printf("Bogus override rejected, as expected: %v\n", err)
+	os.Unsetenv(gopython.LibPythonEnv)
+
+	fmt.Println("\nAll tests completed!")
+}
+
+// checkSearch runs DiscoverLibPython and checks it against the candidates:
+// it must return the first one that exists, or ErrLibPythonNotFound when none
+// does. It returns the discovered path, empty when nothing was found.
+func checkSearch() (string, error) {
+	candidates := gopython.LibPythonCandidates()
+	if len(candidates) == 0 {
+		return "", errors.New("no candidates")
+	}
+	want := ""
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			want = candidate
+			break
+		}
+	}
+
+	got, err := gopython.DiscoverLibPython()
+	if want == "" {
+		if !errors.Is(err, gopython.ErrLibPythonNotFound) {
+			return "", fmt.Errorf("DiscoverLibPython = %q, %v, want ErrLibPythonNotFound", got, err)
+		}
+		return "", nil
+	}
+	if err != nil || got != want {
+		return "", fmt.Errorf("DiscoverLibPython = %q, %v, want %q", got, err, want)
+	}
+	return got, nil
+}
+
+// checkLoads creates a runtime from libpythonPath, initializes it and makes a call
+func checkLoads(libpythonPath string) error {
+	py, err := gopython.NewPureGoPython(libpythonPath)
+	if err != nil {
+		return err
+	}
+	if err := py.Initialize(); err != nil {
+		return err
+	}
+	defer py.Finalize()
+
+	result, err := py.CallFunction("math", "sqrt", 16.0)
+	if err != nil {
+		return err
+	}
+	if result != 4.0 {
+		return fmt.Errorf("math.sqrt(16) = %v", result)
+	}
+	return nil
+}
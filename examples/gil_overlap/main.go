@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/develerltd/gopython310"
+)
+
+// Measures how long a fast call waits while another goroutine is inside a
+// slow Python call that releases the GIL (time.sleep), with the default
+// mutex serialization and with SetTrueGIL
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal("Usage: go run examples/gil_overlap/main.go <path-to-libpython3.10.so>")
+	}
+
+	libpythonPath := os.Args[1]
+
+	fmt.Println("=== GIL overlap benchmark ===")
+	fmt.Println("A 1s time.sleep call runs in one goroutine while another makes fast calls")
+
+	for _, trueGIL := range []bool{false, true} {
+		mode := "mutex (default)"
+		if trueGIL {
+			mode = "SetTrueGIL(true)"
+		}
+
+		latency, total, err := measure(libpythonPath, trueGIL)
+		if err != nil {
+			log.Fatalf("%s: %v", mode, err)
+		}
+		fmt.Printf("%-18s first fast call waited %v, 10 fast calls + sleep took %v\n", mode+":", latency.Round(time.Microsecond), total.Round(time.Millisecond))
+	}
+}
+
+func measure(libpythonPath string, trueGIL bool) (time.Duration, time.Duration, error) {
+	py, err := gopython.NewPureGoPython(libpythonPath)
+	if err != nil {
+		return 0, 0, err
+	}
+	if err := py.SetTrueGIL(trueGIL); err != nil {
+		return 0, 0, err
+	}
+	if err := py.Initialize(); err != nil {
+		return 0, 0, err
+	}
+	defer py.Finalize()
+
+	start := time.Now()
+	slowDone := make(chan error, 1)
+	go func() {
+		_, err := py.CallFunction("time", "sleep", 1.0)
+		slowDone <- err
+	}()
+
+	// Give the slow call time to enter Python
+	time.Sleep(50 * time.Millisecond)
+
+	var latency time.Duration
+	for i := 0; i < 10; i++ {
+		callStart := time.Now()
+		if _, err := py.CallFunction("math", "sqrt", float64(i)); err != nil {
+			return 0, 0, err
+		}
+		if i == 0 {
+			latency = time.Since(callStart)
+		}
+	}
+
+	// Settings can change while the slow call runs and apply to later calls
+	if err := py.SetIntAsPlatformInt(true); err != nil {
+		return 0, 0, err
+	}
+	if n, err := py.CallFunction("builtins", "len", "abc"); err != nil {
+		return 0, 0, err
+	} else if _, ok := n.(int); !ok {
+		return 0, 0, fmt.Errorf("len returned %T after SetIntAsPlatformInt(true)", n)
+	}
+
+	if err := <-slowDone; err != nil {
+		return 0, 0, err
+	}
+	return latency, time.Since(start), nil
+}
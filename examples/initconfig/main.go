@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/develerltd/gopython310"
+)
+
+// Demonstrates startup options: isolated mode ignoring PYTHONPATH, no site
+// import, optimization and no bytecode writing
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal("Usage: go run examples/initconfig/main.go <path-to-libpython3.10.so>")
+	}
+
+	// A host setting that isolated mode must ignore
+	hostPath, err := os.MkdirTemp("", "gopython-host-path")
+	if err != nil {
+		log.Fatalf("Failed to create temporary directory: %v", err)
+	}
+	defer os.RemoveAll(hostPath)
+	os.Setenv("PYTHONPATH", hostPath)
+
+	py, err := gopython.NewPureGoPython(os.Args[1])
+	if err != nil {
+		log.Fatalf("Failed to create Python runtime: %v", err)
+	}
+
+	fmt.Println("=== InitializeWithConfig ===")
+	if err := py.InitializeWithConfig(gopython.InitConfig{OptimizeLevel: 3}); err == nil {
+		fmt.Println("❌ Expected an invalid optimize level to be rejected")
+	} else {
+		fmt.Printf("✅ Invalid optimize level rejected: %v\n", err)
+	}
+
+	err = py.InitializeWithConfig(gopython.InitConfig{
+		Isolated:          true,
+		NoSite:            true,
+		OptimizeLevel:     1,
+		DontWriteBytecode: true,
+		ProgramName:       "gopython-app",
+	})
+	if err != nil {
+		log.Fatalf("Failed to initialize Python: %v", err)
+	}
+	defer py.Finalize()
+
+	err = py.RunString(fmt.Sprintf(`
+import sys
+checks = {
+    "isolated": sys.flags.isolated == 1,
+    "environment ignored": sys.flags.ignore_environment == 1 and %q not in sys.path,
+    "no user site": sys.flags.no_user_site == 1,
+    "site not imported": sys.flags.no_site == 1 and "site" not in sys.modules,
+    "optimized": sys.flags.optimize == 1 and not __debug__,
+    "no bytecode": sys.dont_write_bytecode,
+}
+`, hostPath))
+	if err != nil {
+		log.Fatalf("Failed to inspect interpreter flags: %v", err)
+	}
+	checks, err := py.GetGlobal("checks")
+	if err != nil {
+		log.Fatalf("Failed to read checks: %v", err)
+	}
+	for name, ok := range checks.(map[string]interface{}) {
+		if ok == true {
+			fmt.Printf("✅ %s\n", name)
+		} else {
+			fmt.Printf("❌ %s\n", name)
+		}
+	}
+}
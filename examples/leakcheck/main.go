@@ -0,0 +1,243 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/develerltd/gopython310"
+)
+
+// workload is a named sequence of calls repeated by CheckLeaks
+type workload struct {
+	name string
+	run  func() error
+}
+
+// ignore drops the expected error of a call made to exercise an error path
+func ignore(_ interface{}, _ error) error {
+	return nil
+}
+
+// Repeats common calls, including failing ones, and checks that the
+// interpreter doesn't grow with them
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal("Usage: go run examples/leakcheck/main.go <path-to-libpython3.10.so>")
+	}
+
+	py, err := gopython.NewPureGoPython(os.Args[1])
+	if err != nil {
+		log.Fatalf("Failed to create Python runtime: %v", err)
+	}
+	if err := py.Initialize(); err != nil {
+		log.Fatalf("Failed to initialize Python: %v", err)
+	}
+	defer py.Finalize()
+
+	code := `
+class Point:
+    def __init__(self, x, y):
+        self.x = x
+        self.y = y
+
+def echo(*args):
+    return list(args)
+
+def fail(message):
+    raise ValueError(message)
+
+def make_point():
+    return Point(1, 2)
+
+def numbers(n):
+    for i in range(n):
+        yield i
+
+def configure(*args, **kwargs):
+    return {"args": list(args), "kwargs": kwargs}
+
+def as_text(value):
+    return repr(value)
+
+async def later(value):
+    return value * 2
+
+state = {"calls": 0}
+`
+	if err := py.RunString(code); err != nil {
+		log.Fatalf("Failed to define workload functions: %v", err)
+	}
+
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	nested := map[string]interface{}{
+		"rows":  []interface{}{1, 2.5, "three", []byte("four"), true, nil},
+		"inner": map[interface{}]interface{}{1: "one", "two": []int{2, 2}},
+		"set":   gopython.SetValue{1, 2, 3},
+		"when":  time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		"span":  90 * time.Second,
+		"huge":  huge,
+	}
+
+	workloads := []workload{
+		{"convert nested values", func() error {
+			_, err := py.CallFunction("__main__", "echo", nested, gopython.ByteArray("abc"), gopython.UUID("12345678-1234-5678-1234-567812345678"))
+			return err
+		}},
+		{"unconvertible argument", func() error {
+			return ignore(py.CallFunction("__main__", "echo", []interface{}{1, "two", struct{}{}}))
+		}},
+		{"unconvertible result", func() error {
+			return ignore(py.CallFunction("__main__", "make_point"))
+		}},
+		{"Python exception", func() error {
+			return ignore(py.CallFunction("__main__", "fail", "expected"))
+		}},
+		{"missing function and module", func() error {
+			ignore(py.CallFunction("__main__", "no_such_function"))
+			return ignore(py.CallFunction("no_such_module_for_leaks", "f"))
+		}},
+		{"run code", func() error {
+			if err := py.RunString("state['calls'] += 1"); err != nil {
+				return err
+			}
+			py.RunStringCaptured("raise KeyError('expected')")
+			_, err := py.GetGlobal("state")
+			return err
+		}},
+		{"captured output", func() error {
+			_, _, err := py.RunStringCaptured("print('out'); import sys; print('err', file=sys.stderr)")
+			return err
+		}},
+		{"handles", func() error {
+			point, err := py.CallFunctionRaw("__main__", "make_point")
+			if err != nil {
+				return err
+			}
+			defer point.Close()
+			if _, err := py.GetAttr(point, "x"); err != nil {
+				return err
+			}
+			if err := py.SetAttr(point, "y", 5); err != nil {
+				return err
+			}
+			value, handles, err := py.ConvertDeepOrHandle(point)
+			gopython.CloseAll(value)
+			_ = handles
+			return err
+		}},
+		{"keyword arguments", func() error {
+			_, err := py.CallFunctionKwargs("__main__", "configure", []interface{}{1, "a"}, map[string]interface{}{"depth": 3, "rows": []int{1, 2}})
+			if err != nil {
+				return err
+			}
+			_, err = py.ResultAsString("__main__", "as_text", 1.5)
+			return err
+		}},
+		{"instances", func() error {
+			point, err := py.NewInstance("__main__", "Point", 1, 2)
+			if err != nil {
+				return err
+			}
+			defer point.Close()
+			if _, err := py.Vars(point); err != nil {
+				return err
+			}
+			if _, err := py.EvalIn(point, "x + y"); err != nil {
+				return err
+			}
+			return ignore(py.CallMethod(point, "no_such_method"))
+		}},
+		{"isolated code", func() error {
+			_, err := py.RunIsolated("total = sum(range(10))\nnames = ['a', 'b']")
+			return err
+		}},
+		{"coroutine and context", func() error {
+			if _, err := py.RunCoroutine("__main__", "later", 21); err != nil {
+				return err
+			}
+			_, err := py.CallFunctionContext(context.Background(), "__main__", "echo", 1)
+			return err
+		}},
+		{"iteration", func() error {
+			generator, err := py.CallFunctionRaw("__main__", "numbers", 20)
+			if err != nil {
+				return err
+			}
+			defer generator.Close()
+			_, err = py.CollectIterator(generator, 0)
+			return err
+		}},
+		{"callbacks", func() error {
+			progress, err := py.WithProgress(func(float64, string) {})
+			if err != nil {
+				return err
+			}
+			defer progress.Close()
+			_, err = py.CallCallable(progress, 0.5, "half")
+			return err
+		}},
+		{"channel iterator", func() error {
+			ch := make(chan interface{}, 5)
+			for i := 0; i < 5; i++ {
+				ch <- i
+			}
+			close(ch)
+			it, err := py.WrapChannel(ch)
+			if err != nil {
+				return err
+			}
+			defer it.Close()
+			_, err = py.CallFunction("builtins", "sum", it)
+			return err
+		}},
+		{"define and bind", func() error {
+			funcs, err := py.DefineAndBind("def bound_twice(x):\n    return x * 2")
+			if err != nil {
+				return err
+			}
+			_, err = funcs["bound_twice"](21)
+			return err
+		}},
+		{"batch", func() error {
+			_, err := py.CallBatch([]gopython.Call{
+				{Module: "__main__", Function: "echo", Args: []interface{}{1, "a"}},
+				{Module: "__main__", Function: "fail", Args: []interface{}{"expected"}},
+			})
+			return err
+		}},
+	}
+
+	leaks := 0
+	for _, w := range workloads {
+		report, err := py.CheckLeaks(2000, w.run)
+		switch {
+		case errors.Is(err, gopython.ErrLeakDetected):
+			leaks++
+			fmt.Printf("LEAK %-28s %v\n", w.name, err)
+		case err != nil:
+			leaks++
+			fmt.Printf("FAIL %-28s %v\n", w.name, err)
+		default:
+			growth := report.Growth()
+			fmt.Printf("ok   %-28s blocks %+d, objects %+d over %d rounds\n", w.name, growth.Blocks, growth.Objects, report.Rounds)
+		}
+	}
+
+	// A deliberately leaking workload must be caught
+	py.RunString("leaked = []")
+	_, err = py.CheckLeaks(200, func() error {
+		return py.RunString("leaked.append(object())")
+	})
+	fmt.Printf("deliberate leak detected: %v\n", errors.Is(err, gopython.ErrLeakDetected))
+
+	if leaks > 0 {
+		fmt.Printf("\n%d workloads leaked or failed\n", leaks)
+		os.Exit(1)
+	}
+	fmt.Println("\nNo leaks found")
+}
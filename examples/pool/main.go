@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/develerltd/gopython310"
+)
+
+// Demonstrates state isolation between the sub-interpreters of a Pool and
+// call overlap while pooled code waits with the GIL released
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal("Usage: go run examples/pool/main.go <path-to-libpython3.10.so>")
+	}
+
+	py, err := gopython.NewPureGoPython(os.Args[1])
+	if err != nil {
+		log.Fatalf("Failed to create Python runtime: %v", err)
+	}
+	if err := py.SetTrueGIL(true); err != nil {
+		log.Fatalf("Failed to enable true GIL management: %v", err)
+	}
+	if err := py.Initialize(); err != nil {
+		log.Fatalf("Failed to initialize Python: %v", err)
+	}
+	defer py.Finalize()
+
+	fmt.Println("=== Sub-interpreter pool ===")
+	pool, err := py.NewPool(4)
+	if err != nil {
+		log.Fatalf("Failed to create pool: %v", err)
+	}
+	defer pool.Close()
+
+	setup := `
+import sys
+import time
+
+counter = 0
+
+def bump():
+    global counter
+    counter += 1
+    return counter
+
+def interpreter_id():
+    return id(sys.modules)
+
+def slow(seconds):
+    time.sleep(seconds)
+    return seconds
+`
+	if err := pool.RunEach(setup); err != nil {
+		log.Fatalf("Failed to set up pool: %v", err)
+	}
+
+	// Test 1: every sub-interpreter has its own modules and globals
+	fmt.Println("\nTest 1: Isolated state")
+	ids := map[interface{}]bool{}
+	for i := 0; i < 2*pool.Size(); i++ {
+		id, err := pool.CallFunction("__main__", "interpreter_id")
+		if err != nil {
+			log.Fatalf("interpreter_id failed: %v", err)
+		}
+		ids[id] = true
+	}
+	fmt.Printf("Distinct sys.modules seen across %d calls: %d\n", 2*pool.Size(), len(ids))
+	if _, err := py.CallFunction("__main__", "bump"); err != nil {
+		fmt.Printf("✅ Main interpreter does not see pooled globals: %v\n", err)
+	} else {
+		fmt.Println("❌ Pooled function leaked into the main interpreter")
+	}
+
+	// Test 2: calls waiting with the GIL released overlap
+	fmt.Println("\nTest 2: Overlapping sleeps")
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i := 0; i < pool.Size(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := pool.CallFunction("__main__", "slow", 0.3); err != nil {
+				fmt.Printf("slow failed: %v\n", err)
+			}
+		}()
+	}
+	wg.Wait()
+	fmt.Printf("%d calls sleeping 300ms each took %v\n", pool.Size(), time.Since(start).Round(10*time.Millisecond))
+
+	// Test 3: calls after Close are rejected
+	fmt.Println("\nTest 3: Close")
+	if err := pool.Close(); err != nil {
+		fmt.Printf("Close failed: %v\n", err)
+	}
+	if _, err := pool.CallFunction("__main__", "bump"); err != nil {
+		fmt.Printf("✅ Call after Close rejected: %v\n", err)
+	}
+	if result, err := py.CallFunction("math", "sqrt", 16.0); err == nil {
+		fmt.Printf("✅ Main interpreter still works: sqrt(16) = %v\n", result)
+	} else {
+		fmt.Printf("❌ Main interpreter failed after Close: %v\n", err)
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/develerltd/gopython310"
+)
+
+// refcountCase is an operation and the change in the reference count of an
+// object it is expected to leave behind
+type refcountCase struct {
+	name  string
+	obj   *gopython.PyHandle
+	run   func() error
+	delta int64
+	reset string // Python code undoing the references the operation keeps
+}
+
+// refcountOf returns the reference count sys.getrefcount reports for the
+// object held by h. The count includes the references taken by the call
+// itself, so only compare counts taken with refcountOf.
+func refcountOf(py *gopython.PureGoPython, h *gopython.PyHandle) (int64, error) {
+	result, err := py.CallFunction("sys", "getrefcount", h)
+	if err != nil {
+		return 0, err
+	}
+	count, ok := result.(int64)
+	if !ok {
+		return 0, fmt.Errorf("sys.getrefcount returned %T", result)
+	}
+	return count, nil
+}
+
+// Checks the reference ownership rules of the conversions: each operation
+// must leave the reference counts of the objects it touches exactly where
+// Python code doing the same would leave them
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal("Usage: go run examples/refcount/main.go <path-to-libpython3.10.so>")
+	}
+
+	py, err := gopython.NewPureGoPython(os.Args[1])
+	if err != nil {
+		log.Fatalf("Failed to create Python runtime: %v", err)
+	}
+	if err := py.Initialize(); err != nil {
+		log.Fatalf("Failed to initialize Python: %v", err)
+	}
+	defer py.Finalize()
+
+	code := `
+import sys
+
+sentinel = object()
+text = ''.join(['refcount', '-', 'text'])
+kept = None
+
+def get_sentinel():
+    return sentinel
+
+def get_text():
+    return text
+
+def ignore(*args, **kwargs):
+    pass
+
+def keep(value):
+    global kept
+    kept = value
+
+def keep_args(*args):
+    global kept
+    kept = args
+
+def keep_kwargs(**kwargs):
+    global kept
+    kept = kwargs
+
+def text_containers():
+    return [text, (text, [text]), {'key': text, 'nested': {'deeper': text}}, {text, 1}]
+
+def item_refcounts():
+    # kept[0] and kept['value'] hold objects converted from Go; fresh ones
+    # built by Python and held the same way must have the same count
+    fresh_list = [''.join(['go', '-', 'string'])]
+    fresh_dict = {'value': ''.join(['go', '-', 'value'])}
+    return [sys.getrefcount(kept[0][0]), sys.getrefcount(fresh_list[0]),
+            sys.getrefcount(kept[1]['value']), sys.getrefcount(fresh_dict['value'])]
+`
+	if err := py.RunString(code); err != nil {
+		log.Fatalf("Failed to define refcount helpers: %v", err)
+	}
+
+	sentinel, err := py.CallFunctionRaw("__main__", "get_sentinel")
+	if err != nil {
+		log.Fatalf("Failed to get sentinel: %v", err)
+	}
+	defer sentinel.Close()
+	text, err := py.CallFunctionRaw("__main__", "get_text")
+	if err != nil {
+		log.Fatalf("Failed to get text: %v", err)
+	}
+	defer text.Close()
+
+	cases := []refcountCase{
+		// goToPython returns a new reference for a handle, which the argument
+		// tuple steals and drops with the tuple
+		{name: "*PyHandle argument", obj: sentinel, run: func() error {
+			_, err := py.CallFunction("__main__", "ignore", sentinel)
+			return err
+		}},
+		{name: "*PyHandle argument kept by Python", obj: sentinel, delta: 1, reset: "kept = None", run: func() error {
+			_, err := py.CallFunction("__main__", "keep", sentinel)
+			return err
+		}},
+		// PyList_SetItem steals the reference made for each item
+		{name: "list items", obj: sentinel, run: func() error {
+			_, err := py.CallFunction("__main__", "ignore", []interface{}{sentinel, sentinel, sentinel})
+			return err
+		}},
+		{name: "list items kept by Python", obj: sentinel, delta: 3, reset: "kept = None", run: func() error {
+			_, err := py.CallFunction("__main__", "keep", []interface{}{sentinel, sentinel, sentinel})
+			return err
+		}},
+		// PyTuple_SetItem steals the reference made for each argument
+		{name: "tuple items", obj: sentinel, run: func() error {
+			_, err := py.CallFunction("__main__", "ignore", sentinel, sentinel)
+			return err
+		}},
+		{name: "tuple items kept by Python", obj: sentinel, delta: 2, reset: "kept = None", run: func() error {
+			_, err := py.CallFunction("__main__", "keep_args", sentinel, sentinel)
+			return err
+		}},
+		// PyDict_SetItemString does not steal, so the caller drops its own
+		// reference to the value
+		{name: "dict values", obj: sentinel, run: func() error {
+			_, err := py.CallFunction("__main__", "ignore", map[string]interface{}{"a": sentinel, "b": []interface{}{sentinel}})
+			return err
+		}},
+		{name: "dict values kept by Python", obj: sentinel, delta: 2, reset: "kept = None", run: func() error {
+			_, err := py.CallFunction("__main__", "keep", map[string]interface{}{"a": sentinel, "b": sentinel})
+			return err
+		}},
+		{name: "keyword arguments", obj: sentinel, run: func() error {
+			_, err := py.CallFunctionKwargs("__main__", "ignore", nil, map[string]interface{}{"value": sentinel})
+			return err
+		}},
+		{name: "keyword arguments kept by Python", obj: sentinel, delta: 1, reset: "kept = None", run: func() error {
+			_, err := py.CallFunctionKwargs("__main__", "keep_kwargs", nil, map[string]interface{}{"value": sentinel})
+			return err
+		}},
+		{name: "global set from Go", obj: sentinel, delta: 1, reset: "del alias", run: func() error {
+			return py.SetGlobal("alias", sentinel)
+		}},
+		// pythonToGo is given borrowed references to container items and
+		// must neither keep nor drop a reference to them
+		{name: "converting borrowed container items", obj: text, run: func() error {
+			_, err := py.CallFunction("__main__", "text_containers")
+			return err
+		}},
+		{name: "converting a borrowed global", obj: text, run: func() error {
+			_, err := py.GetGlobal("text")
+			return err
+		}},
+		{name: "converting an attribute", obj: text, run: func() error {
+			_, err := py.GetModuleAttr("__main__", "text")
+			return err
+		}},
+	}
+
+	failures := 0
+	for _, c := range cases {
+		before, err := refcountOf(py, c.obj)
+		if err != nil {
+			log.Fatalf("Failed to read reference count: %v", err)
+		}
+		// Repeat the operation so a one-off reference is told apart from
+		// one leaked or dropped per call
+		for i := 0; i < 3; i++ {
+			if err := c.run(); err != nil {
+				log.Fatalf("%s failed: %v", c.name, err)
+			}
+		}
+		after, err := refcountOf(py, c.obj)
+		if err != nil {
+			log.Fatalf("Failed to read reference count: %v", err)
+		}
+
+		if got := after - before; got != c.delta {
+			fmt.Printf("❌ %s: reference count changed by %+d, want %+d\n", c.name, got, c.delta)
+			failures++
+		} else {
+			fmt.Printf("✅ %s: reference count changed by %+d\n", c.name, got)
+		}
+		if c.reset != "" {
+			if err := py.RunString(c.reset); err != nil {
+				log.Fatalf("Failed to reset %s: %v", c.name, err)
+			}
+		}
+		if restored, _ := refcountOf(py, c.obj); restored != before {
+			fmt.Printf("❌ %s: reference count %d after releasing, want %d\n", c.name, restored, before)
+			failures++
+		}
+	}
+
+	// goToPython returns exactly one reference for a new object: once a
+	// container has stolen it, the object is only held by the container
+	_, err = py.CallFunction("__main__", "keep", []interface{}{
+		[]interface{}{"go-string"},
+		map[string]interface{}{"value": "go-value"},
+	})
+	if err != nil {
+		log.Fatalf("Failed to keep converted values: %v", err)
+	}
+	counts, err := py.CallFunction("__main__", "item_refcounts")
+	if err != nil {
+		log.Fatalf("Failed to count references: %v", err)
+	}
+	c := counts.([]interface{})
+	if c[0] != c[1] || c[2] != c[3] {
+		fmt.Printf("❌ new objects from Go: list item count %v, want %v; dict value count %v, want %v\n", c[0], c[1], c[2], c[3])
+		failures++
+	} else {
+		fmt.Println("✅ new objects from Go are owned by their container alone")
+	}
+
+	if failures > 0 {
+		log.Fatalf("%d reference count checks failed", failures)
+	}
+	fmt.Println("All reference count checks passed")
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/big"
+	"os"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/develerltd/gopython310"
+)
+
+// roundTripCase is a Go value and what it is expected to come back as after
+// a round trip through Python
+type roundTripCase struct {
+	name  string
+	value interface{}
+	want  interface{}
+	equal func(got, want interface{}) bool // Optional, defaults to reflect.DeepEqual
+}
+
+// Checks how each supported Go type survives conversion to Python and back
+func main() {
+	if len(os.Args) < 2 {
+		log.Fatal("Usage: go run examples/roundtrip/main.go <path-to-libpython3.10.so>")
+	}
+
+	py, err := gopython.NewPureGoPython(os.Args[1])
+	if err != nil {
+		log.Fatalf("Failed to create Python runtime: %v", err)
+	}
+	if err := py.Initialize(); err != nil {
+		log.Fatalf("Failed to initialize Python: %v", err)
+	}
+	defer py.Finalize()
+
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	when := time.Date(2024, time.March, 9, 14, 30, 15, 123456000, time.FixedZone("CET", 3600))
+
+	cases := []roundTripCase{
+		{name: "string", value: "héllo, wörld", want: "héllo, wörld"},
+		{name: "empty string", value: "", want: ""},
+		{name: "int", value: 42, want: int64(42)},
+		{name: "int8", value: int8(-8), want: int64(-8)},
+		{name: "int64 min", value: int64(math.MinInt64), want: int64(math.MinInt64)},
+		{name: "uint32", value: uint32(math.MaxUint32), want: int64(math.MaxUint32)},
+		{name: "uint64 max", value: uint64(math.MaxUint64), want: new(big.Int).SetUint64(math.MaxUint64), equal: bigEqual},
+		{name: "*big.Int", value: huge, want: huge, equal: bigEqual},
+		{name: "float64", value: 3.25, want: 3.25},
+		{name: "float32", value: float32(1.5), want: 1.5},
+		{name: "complex128", value: complex(1, -2), want: complex(1, -2)},
+		{name: "bool true", value: true, want: true},
+		{name: "bool false", value: false, want: false},
+		{name: "nil", value: nil, want: nil},
+		{name: "time.Duration", value: 90*time.Minute + 5*time.Microsecond, want: 90*time.Minute + 5*time.Microsecond},
+		{name: "time.Time", value: when, want: when, equal: timeEqual},
+		{name: "time of day", value: time.Date(0, time.January, 1, 9, 5, 30, 250000, time.FixedZone("CET", 3600)), want: time.Date(0, time.January, 1, 9, 5, 30, 250000, time.FixedZone("CET", 3600)), equal: timeEqual},
+		{name: "UUID", value: gopython.UUID("123e4567-e89b-12d3-a456-426614174000"), want: gopython.UUID("123e4567-e89b-12d3-a456-426614174000")},
+		{name: "[]byte", value: []byte{0, 1, 254, 255}, want: []byte{0, 1, 254, 255}},
+		{name: "ByteArray", value: gopython.ByteArray("mutable"), want: []byte("mutable")},
+		{name: "[]interface{}", value: []interface{}{1, "two", 3.0}, want: []interface{}{int64(1), "two", 3.0}},
+		{name: "[]string", value: []string{"a", "b"}, want: []interface{}{"a", "b"}},
+		{name: "[3]int", value: [3]int{1, 2, 3}, want: []interface{}{int64(1), int64(2), int64(3)}},
+		{name: "map[string][]float64", value: map[string][]float64{"xs": {0.5, 1.5}}, want: map[string]interface{}{"xs": []interface{}{0.5, 1.5}}},
+		{name: "map[int]string", value: map[int]string{1: "one"}, want: map[interface{}]interface{}{int64(1): "one"}},
+		{name: "map[string]interface{}", value: map[string]interface{}{"a": 1, "b": []interface{}{true}}, want: map[string]interface{}{"a": int64(1), "b": []interface{}{true}}},
+		{name: "SetValue", value: gopython.SetValue{"x", "y", "x"}, want: []string{"x", "y"}, equal: setEqual},
+		{name: "FrozenSetValue", value: gopython.FrozenSetValue{"z", "y"}, want: []string{"y", "z"}, equal: setEqual},
+		{name: "OrderedDictValue", value: gopython.OrderedDictValue{{Key: "b", Value: 2}, {Key: "a", Value: 1}}, want: map[string]interface{}{"b": int64(2), "a": int64(1)}},
+	}
+
+	// A JSON-shaped document mixing generic and typed containers at every
+	// level; what comes back is the generic form of the same document
+	document := map[string]interface{}{
+		"id":      7,
+		"name":    "sensor",
+		"active":  true,
+		"ratio":   0.25,
+		"missing": nil,
+		"tags":    []string{"outdoor", "north"},
+		"readings": []map[string]interface{}{
+			{"at": "2024-01-01", "values": []float64{1.5, 2.5}},
+			{"at": "2024-01-02", "values": []float64{}},
+		},
+		"limits": map[string][]int{"warn": {10, 20}, "alarm": {30}},
+		"nested": map[string]interface{}{"deeper": []interface{}{[]interface{}{1, "two"}, map[string]bool{"ok": true}}},
+	}
+	cases = append(cases, roundTripCase{
+		name:  "nested document",
+		value: document,
+		want: map[string]interface{}{
+			"id":      int64(7),
+			"name":    "sensor",
+			"active":  true,
+			"ratio":   0.25,
+			"missing": nil,
+			"tags":    []interface{}{"outdoor", "north"},
+			"readings": []interface{}{
+				map[string]interface{}{"at": "2024-01-01", "values": []interface{}{1.5, 2.5}},
+				map[string]interface{}{"at": "2024-01-02", "values": []interface{}{}},
+			},
+			"limits": map[string]interface{}{"warn": []interface{}{int64(10), int64(20)}, "alarm": []interface{}{int64(30)}},
+			"nested": map[string]interface{}{"deeper": []interface{}{[]interface{}{int64(1), "two"}, map[string]interface{}{"ok": true}}},
+		},
+	})
+
+	failures := 0
+	for _, c := range cases {
+		got, err := gopython.RoundTrip(py, c.value)
+		if err != nil {
+			fmt.Printf("❌ %s: %v\n", c.name, err)
+			failures++
+			continue
+		}
+
+		equal := c.equal
+		if equal == nil {
+			equal = reflect.DeepEqual
+		}
+		if !equal(got, c.want) {
+			fmt.Printf("❌ %s: got %#v (%T), want %#v (%T)\n", c.name, got, got, c.want, c.want)
+			failures++
+			continue
+		}
+		fmt.Printf("✅ %s → %T\n", c.name, got)
+	}
+
+	// Tuples nested in dicts and lists come back as slices too
+	if err := py.RunString("def tuple_document():\n    return {'point': (1, 2), 'path': [(0, {'label': ('a',)})]}"); err != nil {
+		log.Fatalf("Failed to define tuple_document: %v", err)
+	}
+	got, err := py.CallFunction("__main__", "tuple_document")
+	want := map[string]interface{}{
+		"point": []interface{}{int64(1), int64(2)},
+		"path":  []interface{}{[]interface{}{int64(0), map[string]interface{}{"label": []interface{}{"a"}}}},
+	}
+	if err != nil || !reflect.DeepEqual(got, want) {
+		fmt.Printf("❌ tuples in containers: got %#v, %v\n", got, err)
+		failures++
+	} else {
+		fmt.Println("✅ tuples in containers → []interface {}")
+	}
+
+	// A datetime.time comes back to Go on January 1 of year 0 and returns to
+	// Python as the same datetime.time
+	if err := py.RunString("import datetime\nlunch = datetime.time(12, 30, 5, 250, tzinfo=datetime.timezone.utc)\ndef is_lunch(value):\n    return type(value) is datetime.time and value == lunch"); err != nil {
+		log.Fatalf("Failed to define lunch: %v", err)
+	}
+	lunch, err := py.GetGlobal("lunch")
+	same, sameErr := py.CallFunction("__main__", "is_lunch", lunch)
+	if err != nil || sameErr != nil || same != true || !lunch.(time.Time).Equal(time.Date(0, time.January, 1, 12, 30, 5, 250000, time.UTC)) {
+		fmt.Printf("❌ datetime.time: got %v (%v), back in Python %v (%v)\n", lunch, err, same, sameErr)
+		failures++
+	} else {
+		fmt.Println("✅ datetime.time → time.Time → datetime.time")
+	}
+
+	// Ints as platform-width Go int: the largest int fits, one past it fails
+	// instead of wrapping, whether int has 32 or 64 bits
+	py.SetIntAsPlatformInt(true)
+	intCases := []struct {
+		name     string
+		value    interface{}
+		want     interface{}
+		overflow bool
+	}{
+		{name: "small int", value: 42, want: 42},
+		{name: "math.MaxInt", value: math.MaxInt, want: math.MaxInt},
+		{name: "math.MinInt", value: math.MinInt, want: math.MinInt},
+		{name: "math.MaxInt + 1", value: new(big.Int).Add(big.NewInt(math.MaxInt), big.NewInt(1)), overflow: true},
+		{name: "math.MinInt - 1", value: new(big.Int).Sub(big.NewInt(math.MinInt), big.NewInt(1)), overflow: true},
+		{name: "ints in a list", value: []interface{}{1, int64(2)}, want: []interface{}{1, 2}},
+	}
+	for _, c := range intCases {
+		got, err := gopython.RoundTrip(py, c.value)
+		switch {
+		case c.overflow && err == nil:
+			fmt.Printf("❌ IntAsPlatformInt %s: expected overflow error, got %#v\n", c.name, got)
+			failures++
+		case c.overflow:
+			fmt.Printf("✅ IntAsPlatformInt %s rejected: %v\n", c.name, err)
+		case err != nil || !reflect.DeepEqual(got, c.want):
+			fmt.Printf("❌ IntAsPlatformInt %s: got %#v (%T), %v\n", c.name, got, got, err)
+			failures++
+		default:
+			fmt.Printf("✅ IntAsPlatformInt %s → %T\n", c.name, got)
+		}
+	}
+	py.SetIntAsPlatformInt(false)
+
+	if failures > 0 {
+		log.Fatalf("%d of %d round trips failed", failures, len(cases))
+	}
+	fmt.Printf("All %d round trips passed\n", len(cases))
+}
+
+// bigEqual compares two *big.Int values
+func bigEqual(got, want interface{}) bool {
+	g, ok := got.(*big.Int)
+	return ok && g.Cmp(want.(*big.Int)) == 0
+}
+
+// timeEqual compares two instants and their UTC offsets
+func timeEqual(got, want interface{}) bool {
+	g, ok := got.(time.Time)
+	if !ok {
+		return false
+	}
+	w := want.(time.Time)
+	_, gotOffset := g.Zone()
+	_, wantOffset := w.Zone()
+	return g.Equal(w) && gotOffset == wantOffset
+}
+
+// setEqual compares the string elements of a converted set with the sorted
+// want, ignoring order since Python sets are unordered
+func setEqual(got, want interface{}) bool {
+	g, ok := got.([]interface{})
+	if !ok {
+		return false
+	}
+	elements := make([]string, len(g))
+	for i, v := range g {
+		s, ok := v.(string)
+		if !ok {
+			return false
+		}
+		elements[i] = s
+	}
+	sort.Strings(elements)
+	return reflect.DeepEqual(elements, want)
+}
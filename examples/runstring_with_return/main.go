@@ -103,18 +103,18 @@ def analyze_text():
 	// Example 4: Using the generic version for type safety
 	fmt.Println("\n=== Example 4: Type-safe generic version ===")
 	code4 := `
-def get_number():
-    return 42
+def get_number(base):
+    return base * 2
 `
 	if err := py.RunString(code4); err != nil {
 		log.Printf("Error: %v", err)
 	} else {
 		// Using the generic CallPyFunction for type safety
-		result, err := gopython.CallPyFunction[interface{}, int64](py, "__main__", "get_number", nil)
+		result, err := gopython.CallPyFunction[int, int64](py, "__main__", "get_number", 21)
 		if err != nil {
 			log.Printf("Error calling function: %v", err)
 		} else {
-			fmt.Printf("get_number() = %d (type: %T)\n", result, result)
+			fmt.Printf("get_number(21) = %d (type: %T)\n", result, result)
 		}
 	}
 
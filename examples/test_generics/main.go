@@ -3,7 +3,9 @@ package main
 import (
 	"fmt"
 	"log"
+	"math"
 	"os"
+	"time"
 
 	"github.com/develerltd/gopython310"
 )
@@ -36,6 +38,25 @@ def get_greeting(name):
 
 def multiply_list(numbers):
     return [x * 2 for x in numbers]
+
+def describe_user(name):
+    return {"name": name, "age": 42, "tags": ["admin", "dev"], "address": {"city": "Turin"}}
+
+def fetch(path):
+    return (200, f"contents of {path}")
+
+def split_stats(numbers):
+    return (min(numbers), max(numbers), {"count": len(numbers)})
+
+def reverse_bytes(data):
+    return data[::-1]
+
+def sha256_digest(data):
+    import hashlib
+    return hashlib.sha256(data).digest()
+
+def bytes_view(data):
+    return memoryview(bytearray(data))
 `
 	if err := py.RunString(code); err != nil {
 		log.Fatalf("Error defining Python functions: %v", err)
@@ -81,5 +102,108 @@ def multiply_list(numbers):
 		fmt.Printf("math.sqrt(%f) = %f\n", input4, result4)
 	}
 
+	// Test 5: Dict result decoded into a struct
+	fmt.Println("\n=== Test 5: Dict output decoded into a struct ===")
+	type Address struct {
+		City string `py:"city"`
+	}
+	type User struct {
+		Name    string   `py:"name"`
+		Age     int      `py:"age"`
+		Tags    []string `py:"tags"`
+		Address Address  `py:"address"`
+	}
+	result5, err := gopython.CallPyFunction[string, User](py, "__main__", "describe_user", "Ada")
+	if err != nil {
+		log.Printf("Error: %v", err)
+	} else {
+		fmt.Printf("describe_user(\"Ada\") = %+v\n", result5)
+	}
+
+	// Test 6: Prepared call reused in a loop
+	fmt.Println("\n=== Test 6: Prepared call ===")
+	greet, err := py.Prepare("__main__", "get_greeting")
+	if err != nil {
+		log.Printf("Error: %v", err)
+	} else {
+		start := time.Now()
+		for i := 0; i < 10000; i++ {
+			if _, err := greet.Call("Go"); err != nil {
+				log.Printf("Error: %v", err)
+				break
+			}
+		}
+		preparedTime := time.Since(start)
+
+		start = time.Now()
+		for i := 0; i < 10000; i++ {
+			if _, err := py.CallFunction("__main__", "get_greeting", "Go"); err != nil {
+				log.Printf("Error: %v", err)
+				break
+			}
+		}
+		fmt.Printf("10000 calls: prepared %v, CallFunction %v\n", preparedTime, time.Since(start))
+	}
+
+	// Test 7: Tuple results unpacked into several typed values
+	fmt.Println("\n=== Test 7: Tuple output unpacked ===")
+	status, body, err := gopython.CallPyFunction2[string, int, string](py, "__main__", "fetch", "/index")
+	if err != nil {
+		log.Printf("Error: %v", err)
+	} else {
+		fmt.Printf("fetch(\"/index\") = %d, %q\n", status, body)
+	}
+	type Meta struct {
+		Count int `py:"count"`
+	}
+	low, high, meta, err := gopython.CallPyFunction3[[]interface{}, float64, float64, Meta](py, "__main__", "split_stats", []interface{}{3, 1.5, 9})
+	if err != nil {
+		log.Printf("Error: %v", err)
+	} else {
+		fmt.Printf("split_stats([3, 1.5, 9]) = %v, %v, %+v\n", low, high, meta)
+	}
+	if _, _, err := gopython.CallPyFunction2[string, int, string](py, "__main__", "get_greeting", "Go"); err != nil {
+		fmt.Printf("Non-tuple result rejected: %v\n", err)
+	}
+
+	// Test 8: Binary data in and out
+	fmt.Println("\n=== Test 8: Bytes input, bytes output ===")
+	payload := []byte("go\x00py\xff")
+	reversed, err := gopython.CallPyFunction[[]byte, []byte](py, "__main__", "reverse_bytes", payload)
+	if err != nil {
+		log.Printf("Error: %v", err)
+	} else {
+		fmt.Printf("reverse_bytes(%q) = %q\n", payload, reversed)
+	}
+	digest, err := gopython.CallPyFunction[[]byte, [32]byte](py, "__main__", "sha256_digest", []byte("abc"))
+	if err != nil {
+		log.Printf("Error: %v", err)
+	} else {
+		fmt.Printf("sha256_digest(\"abc\") = %x\n", digest)
+	}
+	view, err := gopython.CallPyFunction[[]byte, []byte](py, "__main__", "bytes_view", payload)
+	if err != nil {
+		log.Printf("Error: %v", err)
+	} else {
+		fmt.Printf("bytes_view(%q) = %q\n", payload, view)
+	}
+
+	// Test 9: A typed function resolved once and called in a loop
+	fmt.Println("\n=== Test 9: TypedFunc ===")
+	sqrt, err := gopython.TypedFunc[float64](py, "math", "sqrt")
+	if err != nil {
+		log.Fatalf("TypedFunc(math.sqrt) failed: %v", err)
+	}
+	for i := 0; i < 1000; i++ {
+		got, err := sqrt(float64(i))
+		if err != nil {
+			log.Fatalf("sqrt(%d) failed: %v", i, err)
+		}
+		if want := math.Sqrt(float64(i)); got != want {
+			log.Fatalf("sqrt(%d) = %v, want %v", i, got, want)
+		}
+	}
+	fmt.Println("math.sqrt matched math.Sqrt for 0..999")
+
 	fmt.Println("\nAll tests completed!")
 }
\ No newline at end of file
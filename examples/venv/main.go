@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 
 	"github.com/develerltd/gopython310"
 )
@@ -17,6 +20,14 @@ func main() {
 	libpythonPath := os.Args[1]
 	venvPath := os.Args[2]
 
+	// Child process started by the PythonHome test
+	if home := os.Getenv(pythonHomeEnv); home != "" {
+		if err := checkPythonHome(libpythonPath, venvPath, home); err != nil {
+			log.Fatalf("PythonHome check failed: %v", err)
+		}
+		return
+	}
+
 	fmt.Printf("Testing virtual environment support\n")
 	fmt.Printf("Libpython: %s\n", libpythonPath)
 	fmt.Printf("Virtual environment: %s\n", venvPath)
@@ -63,26 +74,74 @@ for i, path in enumerate(sys.path):
 		fmt.Printf("Error checking paths: %v\n", err)
 	}
 
+	prefix, basePrefix, err := py.Prefixes()
+	if err != nil {
+		fmt.Printf("Error reading prefixes: %v\n", err)
+	} else if prefix == "" || basePrefix == "" {
+		fmt.Printf("Error: empty prefix (prefix=%q, base_prefix=%q)\n", prefix, basePrefix)
+	} else {
+		fmt.Printf("sys.prefix: %s\n", prefix)
+		fmt.Printf("sys.base_prefix: %s\n", basePrefix)
+	}
+
+	// Paths containing quotes or backslashes (e.g. /tmp/o'brien/venv) must
+	// reach Python unchanged
+	virtualEnv, err := py.CallFunction("os", "getenv", "VIRTUAL_ENV")
+	if err != nil {
+		fmt.Printf("Error reading VIRTUAL_ENV: %v\n", err)
+	} else {
+		fmt.Printf("VIRTUAL_ENV matches the venv path: %v\n", virtualEnv == venvPath)
+	}
+
 	// Test 2: Check available packages
 	fmt.Println("\n=== Test 2: Available Packages ===")
-	packagesCode := `
-try:
-    # Use modern importlib.metadata (Python 3.8+)
-    import importlib.metadata as metadata
-    installed_packages = [dist.metadata['name'] for dist in metadata.distributions()]
-except ImportError:
-    # Fallback to pkg_resources for older Python versions
-    import pkg_resources
-    installed_packages = [d.project_name for d in pkg_resources.working_set]
-
-print(f"Found {len(installed_packages)} installed packages:")
-for pkg in sorted(installed_packages)[:10]:  # Show first 10
-    print(f"  - {pkg}")
-if len(installed_packages) > 10:
-    print(f"  ... and {len(installed_packages) - 10} more")
-`
-	if err := py.RunString(packagesCode); err != nil {
-		fmt.Printf("Error checking packages: %v\n", err)
+	packages, err := py.InstalledPackages()
+	if err != nil {
+		log.Fatalf("Failed to list installed packages: %v", err)
+	}
+	fmt.Printf("Found %d installed packages:\n", len(packages))
+	versions := make(map[string][]string)
+	for _, pkg := range packages {
+		fmt.Printf("  - %s %s\n", pkg.Name, pkg.Version)
+		versions[strings.ToLower(pkg.Name)] = append(versions[strings.ToLower(pkg.Name)], pkg.Version)
+	}
+
+	// A fresh venv has its own pip and setuptools, which must be listed once
+	// with the versions installed in the venv
+	sitePackages, err := gopython.GetVenvSitePackagesPath(venvPath)
+	if err != nil {
+		log.Fatalf("Failed to locate the venv site-packages: %v", err)
+	}
+	for _, name := range []string{"pip", "setuptools"} {
+		distInfo, _ := filepath.Glob(filepath.Join(sitePackages, name+"-*.dist-info"))
+		if len(distInfo) != 1 {
+			log.Fatalf("Expected %s to be installed in the venv, found %v", name, distInfo)
+		}
+		venvVersion := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(distInfo[0]), name+"-"), ".dist-info")
+		if got := versions[name]; len(got) != 1 || got[0] != venvVersion {
+			log.Fatalf("InstalledPackages listed %s versions %v, want only the venv's %s", name, got, venvVersion)
+		}
+		fmt.Printf("%s is listed once, with the venv's version %s\n", name, venvVersion)
+	}
+
+	// Requirements are checked against the installed versions
+	requirements := []struct {
+		spec string
+		want bool
+	}{
+		{"pip>=1.0", true},
+		{"pip<1.0", false},
+		{"gopython-no-such-package>=1.0", false},
+	}
+	for _, r := range requirements {
+		satisfied, err := py.CheckRequirement(r.spec)
+		if err != nil {
+			log.Fatalf("Failed to check requirement %q: %v", r.spec, err)
+		}
+		if satisfied != r.want {
+			log.Fatalf("CheckRequirement(%q) = %v, want %v", r.spec, satisfied, r.want)
+		}
+		fmt.Printf("CheckRequirement(%q) = %v\n", r.spec, satisfied)
 	}
 
 	// Test 3: Try importing packages that might be in venv
@@ -207,8 +266,91 @@ def advanced_math_test(numbers):
 		}
 	}
 
+	// Test 5: Reinitialize with NoSite, configuring sys.path without site
+	fmt.Println("\n=== Test 5: NoSite Configuration ===")
+	if err := py.Finalize(); err != nil {
+		fmt.Printf("Error finalizing: %v\n", err)
+	} else if err := py.InitializeWithVenv(gopython.VirtualEnvConfig{VenvPath: venvPath, NoSite: true}); err != nil {
+		fmt.Printf("Error initializing with NoSite: %v\n", err)
+	} else {
+		sitePackages, _ := gopython.GetVenvSitePackagesPath(venvPath)
+		result, err := py.RunIsolated(fmt.Sprintf(`
+import sys
+site_imported = "site" in sys.modules
+on_path = %q in sys.path
+`, sitePackages))
+		if err != nil {
+			fmt.Printf("Error checking NoSite setup: %v\n", err)
+		} else {
+			fmt.Printf("site imported: %v, venv site-packages on sys.path: %v\n", result["site_imported"], result["on_path"])
+		}
+	}
+
+	// Test 6: Initialize with PythonHome pointing at a copy of the
+	// installation layout, which sys.base_prefix must then report
+	fmt.Println("\n=== Test 6: PythonHome Configuration ===")
+	major, minor, _, err := py.Version()
+	if err != nil {
+		log.Fatalf("Failed to read the version: %v", err)
+	}
+	stdlib, err := py.GetModuleAttr("os", "__file__")
+	if err != nil {
+		log.Fatalf("Failed to locate the standard library: %v", err)
+	}
+	home, err := os.MkdirTemp("", "gopython-home")
+	if err != nil {
+		log.Fatalf("Failed to create home: %v", err)
+	}
+	// <home>/lib/pythonX.Y links to the real standard library
+	libDir := filepath.Join(home, "lib")
+	os.MkdirAll(libDir, 0o755)
+	os.Symlink(filepath.Dir(stdlib.(string)), filepath.Join(libDir, fmt.Sprintf("python%d.%d", major, minor)))
+
+	// The path configuration is only read by the first initialization in a
+	// process, so the check runs in a fresh copy of this example
+	self, err := os.Executable()
+	if err != nil {
+		log.Fatalf("Failed to locate the example binary: %v", err)
+	}
+	child := exec.Command(self, os.Args[1:]...)
+	child.Env = append(os.Environ(), pythonHomeEnv+"="+home)
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
+	err = child.Run()
+	os.RemoveAll(home)
+	if err != nil {
+		log.Fatalf("PythonHome test failed: %v", err)
+	}
+
 	fmt.Println("\n=== Virtual Environment Test Complete ===")
 	fmt.Println("✓ Successfully initialized Python with virtual environment")
 	fmt.Println("✓ Virtual environment packages are accessible")
 	fmt.Println("✓ Go-to-Python function calls work with venv packages")
 }
+
+// pythonHomeEnv holds the PythonHome the child process of the PythonHome
+// test initializes with
+const pythonHomeEnv = "GOPYTHON_EXAMPLE_PYTHONHOME"
+
+// checkPythonHome initializes a fresh runtime with PythonHome set to home and
+// fails unless sys.base_prefix reports it
+func checkPythonHome(libpythonPath, venvPath, home string) error {
+	py, err := gopython.NewPureGoPython(libpythonPath)
+	if err != nil {
+		return err
+	}
+	if err := py.InitializeWithVenv(gopython.VirtualEnvConfig{VenvPath: venvPath, PythonHome: home}); err != nil {
+		return err
+	}
+	defer py.Finalize()
+
+	prefix, err := py.GetModuleAttr("sys", "base_prefix")
+	if err != nil {
+		return err
+	}
+	if prefix != home {
+		return fmt.Errorf("sys.base_prefix is %v, want %s", prefix, home)
+	}
+	fmt.Printf("sys.base_prefix reflects PythonHome: %s\n", prefix)
+	return nil
+}
@@ -0,0 +1,817 @@
+package gopython
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sync"
+)
+
+// PyHandle is a reference to a live Python object that is kept alive on the
+// Python side until Close is called. Handles let Go hold onto objects that do
+// not convert cleanly (or are too large to round-trip) and pass them back into
+// later calls.
+type PyHandle struct {
+	py      *PureGoPython
+	obj     uintptr
+	release func() // Optional cleanup run after the object is released
+}
+
+// newHandle wraps obj in a PyHandle. The handle takes ownership of the
+// reference held by the caller, so obj must be a new (not borrowed) reference.
+func (py *PureGoPython) newHandle(obj uintptr) *PyHandle {
+	return &PyHandle{py: py, obj: obj}
+}
+
+// Close releases the Python object referenced by the handle. It is safe to
+// call Close more than once.
+func (h *PyHandle) Close() error {
+	if h == nil || h.obj == 0 {
+		return nil
+	}
+
+	return h.py.withGIL(func() error {
+		h.drop()
+		return nil
+	})
+}
+
+// drop releases the referenced object with the GIL already held
+func (h *PyHandle) drop() {
+	if h.obj == 0 {
+		return
+	}
+	h.py.safeDecRef(h.obj)
+	h.obj = 0
+	if h.release != nil {
+		h.release()
+		h.release = nil
+	}
+}
+
+// checkHandle verifies that a handle is usable
+func checkHandle(h *PyHandle) error {
+	if h == nil {
+		return errors.New("handle is nil")
+	}
+	if h.obj == 0 {
+		return errors.New("handle is closed")
+	}
+	return nil
+}
+
+// AsHandle reports whether value, an element of a converted result, is a
+// *PyHandle rather than a plain Go value. Results of ConvertDeepOrHandle and
+// CallFunctionRaw can hold handles; walk them with a type switch over
+// []interface{}, map[string]interface{}, map[interface{}]interface{} and
+// OrderedDictValue, calling AsHandle on the leaves:
+//
+//	if h, ok := gopython.AsHandle(item); ok {
+//	    name, _ := py.GetAttr(h, "name")
+//	}
+func AsHandle(value interface{}) (*PyHandle, bool) {
+	h, ok := value.(*PyHandle)
+	return h, ok && h != nil
+}
+
+// CloseAll closes every handle found in value, walking slices, maps (keys
+// included) and OrderedDictValue recursively. Other values are ignored, so
+// it can be called on any converted result, e.g. with defer once the result
+// of ConvertDeepOrHandle is no longer needed.
+func CloseAll(value interface{}) {
+	switch v := value.(type) {
+	case *PyHandle:
+		v.Close()
+	case []interface{}:
+		for _, item := range v {
+			CloseAll(item)
+		}
+	case SetValue:
+		CloseAll([]interface{}(v))
+	case FrozenSetValue:
+		CloseAll([]interface{}(v))
+	case map[string]interface{}:
+		for _, item := range v {
+			CloseAll(item)
+		}
+	case map[interface{}]interface{}:
+		for key, item := range v {
+			CloseAll(key)
+			CloseAll(item)
+		}
+	case OrderedDictValue:
+		for _, kv := range v {
+			CloseAll(kv.Value)
+		}
+	}
+}
+
+// getAttrString returns a new reference to the named attribute of obj, or 0
+// with the Python error indicator cleared if the attribute does not exist
+func (py *PureGoPython) getAttrString(obj uintptr, name string) uintptr {
+	attr := py.pyObjectGetAttrString(obj, stringToCString(name))
+	if attr == 0 {
+		py.pyErrClear()
+	}
+	return attr
+}
+
+// NewInstance imports module, looks up className and calls it with the given
+// arguments, returning the new instance as a handle. The instance stays alive
+// until the handle is closed.
+func (py *PureGoPython) NewInstance(module, className string, args ...interface{}) (*PyHandle, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+
+	result, err := py.withGILReturn(func() (interface{}, error) {
+		instance, err := py.callFunctionObject(module, className, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create instance of '%s': %w", className, err)
+		}
+		return py.newHandle(instance), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*PyHandle), nil
+}
+
+// CallMethod calls the named method on the object referenced by the handle
+// and converts the result to a Go value
+func (py *PureGoPython) CallMethod(h *PyHandle, method string, args ...interface{}) (interface{}, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+	if err := checkHandle(h); err != nil {
+		return nil, err
+	}
+
+	return py.withGILReturn(func() (interface{}, error) {
+		methodObj := py.pyObjectGetAttrString(h.obj, stringToCString(method))
+		if methodObj == 0 {
+			return nil, fmt.Errorf("method '%s' not found: %w", method, py.getPythonError())
+		}
+		defer py.safeDecRef(methodObj)
+
+		resultObj, err := py.callObject(methodObj, args...)
+		if err != nil {
+			return nil, err
+		}
+		defer py.safeDecRef(resultObj)
+
+		return py.pythonToGo(PyObject(resultObj))
+	})
+}
+
+// CallCallable calls the object referenced by the handle, such as a function
+// returned by a factory, a bound method or a functools.partial, and converts
+// the result to a Go value. An error is returned if the object is not callable.
+func (py *PureGoPython) CallCallable(h *PyHandle, args ...interface{}) (interface{}, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+	if err := checkHandle(h); err != nil {
+		return nil, err
+	}
+
+	return py.withGILReturn(func() (interface{}, error) {
+		if py.pyCallableCheck(h.obj) == 0 {
+			return nil, fmt.Errorf("'%s' object is not callable", py.getTypeName(PyObject(h.obj)))
+		}
+
+		resultObj, err := py.callObject(h.obj, args...)
+		if err != nil {
+			return nil, err
+		}
+		defer py.safeDecRef(resultObj)
+
+		return py.pythonToGo(PyObject(resultObj))
+	})
+}
+
+// GetAttr returns the named attribute of the object referenced by the handle,
+// converted to a Go value. An error is returned if the attribute does not exist.
+func (py *PureGoPython) GetAttr(h *PyHandle, name string) (interface{}, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+	if err := checkHandle(h); err != nil {
+		return nil, err
+	}
+
+	return py.withGILReturn(func() (interface{}, error) {
+		attr := py.pyObjectGetAttrString(h.obj, stringToCString(name))
+		if attr == 0 {
+			return nil, fmt.Errorf("failed to get attribute '%s': %w", name, py.getPythonError())
+		}
+		defer py.safeDecRef(attr)
+
+		return py.pythonToGo(PyObject(attr))
+	})
+}
+
+// SetAttr sets the named attribute of the object referenced by the handle to
+// the given Go value
+func (py *PureGoPython) SetAttr(h *PyHandle, name string, value interface{}) error {
+	if !py.IsInitialized() {
+		return ErrNotInitialized
+	}
+	if err := checkHandle(h); err != nil {
+		return err
+	}
+
+	return py.withGIL(func() error {
+		pyValue, err := py.goToPython(value)
+		if err != nil {
+			return fmt.Errorf("failed to convert value for attribute '%s': %w", name, err)
+		}
+		defer py.safeDecRef(uintptr(pyValue))
+
+		// PyObject_SetAttrString doesn't steal the reference
+		if py.pyObjectSetAttrString(h.obj, stringToCString(name), uintptr(pyValue)) != 0 {
+			return fmt.Errorf("failed to set attribute '%s': %w", name, py.getPythonError())
+		}
+		return nil
+	})
+}
+
+// InSet reports whether value is a member of the set or frozenset referenced
+// by the handle. Only value is converted, so membership can be tested against
+// large sets kept on the Python side without copying them into Go.
+func (py *PureGoPython) InSet(h *PyHandle, value interface{}) (bool, error) {
+	if !py.IsInitialized() {
+		return false, ErrNotInitialized
+	}
+	if err := checkHandle(h); err != nil {
+		return false, err
+	}
+	if err := py.requireFeature("sets"); err != nil {
+		return false, err
+	}
+
+	result, err := py.withGILReturn(func() (interface{}, error) {
+		if !py.isSet(PyObject(h.obj)) {
+			return false, fmt.Errorf("handle refers to %s, not a set", py.getTypeName(PyObject(h.obj)))
+		}
+
+		pyValue, err := py.goToPython(value)
+		if err != nil {
+			return false, fmt.Errorf("failed to convert value: %w", err)
+		}
+		defer py.safeDecRef(uintptr(pyValue))
+
+		switch py.pySetContains(h.obj, uintptr(pyValue)) {
+		case 1:
+			return true, nil
+		case 0:
+			return false, nil
+		default:
+			return false, fmt.Errorf("failed to test set membership: %w", py.getPythonError())
+		}
+	})
+	if err != nil {
+		return false, err
+	}
+	return result.(bool), nil
+}
+
+// EvalIn evaluates a Python expression against the object referenced by the
+// handle and converts the result to Go. The object is available as obj and
+// self, and the entries of its __dict__ (or its keys, for a dict) as plain
+// names, so derived values can be computed without defining a function.
+//
+// Example:
+//
+//	total, err := py.EvalIn(order, "sum(line.price for line in obj.lines)")
+//	label, err := py.EvalIn(order, "f'{customer} ({len(lines)} lines)'")
+func (py *PureGoPython) EvalIn(h *PyHandle, expr string) (interface{}, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+	if err := checkHandle(h); err != nil {
+		return nil, err
+	}
+	cExpr, err := codeToCString(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	return py.withGILReturn(func() (interface{}, error) {
+		globals := py.pyDictNew()
+		if globals == 0 {
+			return nil, errors.New("failed to create globals dict")
+		}
+		defer py.safeDecRef(globals)
+		builtins, err := py.importModule("builtins")
+		if err != nil {
+			return nil, err
+		}
+		defer py.safeDecRef(builtins)
+		if py.pyDictSetItemString(globals, stringToCString("__builtins__"), builtins) != 0 {
+			return nil, fmt.Errorf("failed to seed builtins: %w", py.getPythonError())
+		}
+
+		locals := py.pyDictNew()
+		if locals == 0 {
+			return nil, errors.New("failed to create locals dict")
+		}
+		defer py.safeDecRef(locals)
+		if py.isDict(PyObject(h.obj)) {
+			py.copyStringKeys(locals, h.obj)
+		} else if attrs := py.getAttrString(h.obj, "__dict__"); attrs != 0 {
+			if py.isDict(PyObject(attrs)) {
+				py.copyStringKeys(locals, attrs)
+			}
+			py.safeDecRef(attrs)
+		}
+		for _, name := range []string{"obj", "self"} {
+			// PyDict_SetItemString doesn't steal the reference
+			if py.pyDictSetItemString(locals, stringToCString(name), h.obj) != 0 {
+				return nil, fmt.Errorf("failed to bind '%s': %w", name, py.getPythonError())
+			}
+		}
+
+		resultObj := py.pyRunStringFlags(cExpr, pyEvalInput, globals, locals, 0)
+		if resultObj == 0 {
+			return nil, fmt.Errorf("failed to evaluate '%s': %w", expr, py.getPythonError())
+		}
+		defer py.safeDecRef(resultObj)
+
+		return py.pythonToGo(PyObject(resultObj))
+	})
+}
+
+// copyStringKeys copies the entries of src with string keys into dst without
+// GIL management
+func (py *PureGoPython) copyStringKeys(dst, src uintptr) {
+	keys := py.pyDictKeys(src)
+	if keys == 0 {
+		py.pyErrClear()
+		return
+	}
+	defer py.safeDecRef(keys)
+
+	for i := 0; i < py.pyListSize(keys); i++ {
+		// PyList_GetItem and PyDict_GetItem return borrowed references
+		key := py.pyListGetItem(keys, i)
+		if !py.isString(PyObject(key)) {
+			continue
+		}
+		value := py.pyDictGetItem(src, key)
+		if value != 0 && py.pyDictSetItem(dst, key, value) != 0 {
+			py.pyErrClear()
+		}
+	}
+}
+
+// Vars returns the attributes of the object referenced by the handle converted
+// to Go values, similar to Python's vars(). Attributes are read from the
+// instance __dict__ and, for classes using __slots__, from each slot declared
+// anywhere in the class hierarchy. Unset slots are skipped.
+func (py *PureGoPython) Vars(h *PyHandle) (map[string]interface{}, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+	if err := checkHandle(h); err != nil {
+		return nil, err
+	}
+
+	result, err := py.withGILReturn(func() (interface{}, error) {
+		return py.varsUnsafe(h.obj)
+	})
+	values, _ := result.(map[string]interface{})
+	return values, err
+}
+
+// varsUnsafe collects instance attributes without GIL management. With
+// SetSkipUnconvertible collecting errors, the attributes come with the
+// ConversionErrors of their skipped elements.
+func (py *PureGoPython) varsUnsafe(obj uintptr) (_ map[string]interface{}, err error) {
+	result := make(map[string]interface{})
+	found := false
+	opts := py.decode
+	var skipped ConversionErrors
+	defer func() {
+		// Release the placeholders of elements skipped before the failure
+		if err != nil && !isPartial(err) {
+			CloseAll(result)
+		}
+	}()
+
+	if dict := py.getAttrString(obj, "__dict__"); dict != 0 {
+		defer py.safeDecRef(dict)
+		if py.isDict(PyObject(dict)) {
+			found = true
+			values, err := py.pythonDictToMap(PyObject(dict), &opts, 0)
+			if err != nil && !isPartial(err) {
+				return nil, fmt.Errorf("failed to convert __dict__: %w", err)
+			}
+			skipped = appendSkipped(skipped, "__dict__", err)
+			for key, value := range values {
+				result[key] = value
+			}
+		}
+	}
+
+	typeObj := py.pyObjectType(obj)
+	if typeObj == 0 {
+		return nil, errors.New("failed to get object type")
+	}
+	defer py.safeDecRef(typeObj)
+
+	mro := py.getAttrString(typeObj, "__mro__")
+	if mro != 0 {
+		defer py.safeDecRef(mro)
+		for i := 0; i < py.pyTupleSize(mro); i++ {
+			// PyTuple_GetItem returns a borrowed reference
+			cls := py.pyTupleGetItem(mro, i)
+			for _, name := range py.slotNames(cls) {
+				found = true
+				if _, exists := result[name]; exists {
+					continue
+				}
+				value := py.getAttrString(obj, name)
+				if value == 0 {
+					continue // Slot declared but not set
+				}
+				converted, err := py.pythonToGoDepth(PyObject(value), &opts, 0)
+				py.safeDecRef(value)
+				if err != nil && !isPartial(err) {
+					return nil, fmt.Errorf("failed to convert slot '%s': %w", name, err)
+				}
+				skipped = appendSkipped(skipped, fmt.Sprintf("slot '%s'", name), err)
+				result[name] = converted
+			}
+		}
+	}
+
+	if !found {
+		return nil, fmt.Errorf("object of type %s has no __dict__ or __slots__", py.getTypeName(PyObject(obj)))
+	}
+	return result, opts.skippedErrors(skipped)
+}
+
+// slotNames returns the attribute names declared in a class's own __slots__
+func (py *PureGoPython) slotNames(cls uintptr) []string {
+	dict := py.getAttrString(cls, "__dict__")
+	if dict == 0 {
+		return nil
+	}
+	defer py.safeDecRef(dict)
+
+	// Only look at the class's own namespace so inherited __slots__ are not repeated
+	slots := py.getAttrString(dict, "get")
+	if slots == 0 {
+		return nil
+	}
+	defer py.safeDecRef(slots)
+
+	args, err := py.buildArgumentTuple("__slots__")
+	if err != nil {
+		return nil
+	}
+	defer py.safeDecRef(uintptr(args))
+
+	declared := py.pyObjectCallObject(slots, uintptr(args))
+	if declared == 0 {
+		py.pyErrClear()
+		return nil
+	}
+	defer py.safeDecRef(declared)
+
+	var items []uintptr
+	switch {
+	case py.isString(PyObject(declared)):
+		items = []uintptr{declared}
+	case py.isTuple(PyObject(declared)):
+		for i := 0; i < py.pyTupleSize(declared); i++ {
+			items = append(items, py.pyTupleGetItem(declared, i))
+		}
+	case py.isList(PyObject(declared)):
+		for i := 0; i < py.pyListSize(declared); i++ {
+			items = append(items, py.pyListGetItem(declared, i))
+		}
+	}
+
+	var names []string
+	for _, item := range items {
+		cStr := py.pyUnicodeAsUTF8(item)
+		if cStr == nil {
+			py.pyErrClear()
+			continue
+		}
+		name := cStringToGoString(cStr)
+		if name == "__dict__" || name == "__weakref__" {
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// Iterate lazily iterates the object referenced by the handle, such as a
+// generator, sending each converted item on the returned channel. The channel
+// is closed when iteration ends. If iteration or conversion fails, the error
+// is sent as the final value before the channel is closed:
+//
+//	items, cancel, err := py.Iterate(gen)
+//	if err != nil {
+//	    return err
+//	}
+//	defer cancel()
+//	for item := range items {
+//	    if err, ok := item.(error); ok {
+//	        return err
+//	    }
+//	    ...
+//	}
+//
+// The interpreter lock is only held while the next item is produced, so other
+// calls can run between items. Calling cancel stops iteration early and
+// releases the iterator; it is safe to call more than once.
+func (py *PureGoPython) Iterate(h *PyHandle) (<-chan interface{}, func(), error) {
+	if !py.IsInitialized() {
+		return nil, nil, ErrNotInitialized
+	}
+	if err := checkHandle(h); err != nil {
+		return nil, nil, err
+	}
+
+	result, err := py.withGILReturn(func() (interface{}, error) {
+		iterator := py.pyObjectGetIter(h.obj)
+		if iterator == 0 {
+			return nil, fmt.Errorf("object is not iterable: %w", py.getPythonError())
+		}
+		return iterator, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	iterator := result.(uintptr)
+
+	items := make(chan interface{})
+	done := make(chan struct{})
+	finished := make(chan struct{})
+
+	go func() {
+		defer close(finished)
+		defer close(items)
+		defer py.withGIL(func() error {
+			py.safeDecRef(iterator)
+			return nil
+		})
+
+		for {
+			value, err := py.withGILReturn(func() (interface{}, error) {
+				return py.nextItem(iterator)
+			})
+			if err == errIterationDone {
+				return
+			}
+			if isPartial(err) {
+				err = nil // Items keep the placeholders of skipped elements
+			}
+			if err != nil {
+				value = err
+			}
+
+			select {
+			case items <- value:
+			case <-done:
+				CloseAll(value)
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() { close(done) })
+		<-finished
+	}
+	return items, cancel, nil
+}
+
+// errIterationDone signals that an iterator is exhausted
+var errIterationDone = errors.New("iteration done")
+
+// nextItem advances iterator and converts the item without GIL management
+func (py *PureGoPython) nextItem(iterator uintptr) (interface{}, error) {
+	// PyIter_Next returns a new reference, or NULL when exhausted or on error
+	item := py.pyIterNext(iterator)
+	if item == 0 {
+		if py.pyErrOccurred() != 0 {
+			return nil, fmt.Errorf("iteration failed: %w", py.getPythonError())
+		}
+		return nil, errIterationDone
+	}
+	defer py.safeDecRef(item)
+
+	return py.pythonToGo(PyObject(item))
+}
+
+// CollectIterator drains the iterable referenced by the handle into a slice of
+// converted items, stopping after limit items. A limit of zero or less drains
+// the iterator completely, so it must only be used with finite iterators.
+func (py *PureGoPython) CollectIterator(h *PyHandle, limit int) ([]interface{}, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+	if err := checkHandle(h); err != nil {
+		return nil, err
+	}
+
+	result, err := py.withGILReturn(func() (interface{}, error) {
+		iterator := py.pyObjectGetIter(h.obj)
+		if iterator == 0 {
+			return nil, fmt.Errorf("object is not iterable: %w", py.getPythonError())
+		}
+		defer py.safeDecRef(iterator)
+
+		items := []interface{}{}
+		var skipped ConversionErrors
+		for limit <= 0 || len(items) < limit {
+			item, err := py.nextItem(iterator)
+			if err == errIterationDone {
+				break
+			}
+			if err != nil && !isPartial(err) {
+				CloseAll(items)
+				return nil, fmt.Errorf("failed to collect item %d: %w", len(items), err)
+			}
+			skipped = appendSkipped(skipped, fmt.Sprintf("item %d", len(items)), err)
+			items = append(items, item)
+		}
+		return items, py.decode.skippedErrors(skipped)
+	})
+	items, _ := result.([]interface{})
+	return items, err
+}
+
+// ConvertDeepOrHandle converts the object referenced by the handle on a
+// best-effort basis: lists, tuples, sets and dicts are walked like they are
+// by CallFunction, leaves that can be converted become Go values, and any
+// other leaf becomes a *PyHandle in its place instead of failing the whole
+// conversion. The embedded handles are also returned so the caller can close
+// them once done with the result, individually or with CloseAll; h itself
+// stays open.
+//
+// Example:
+//
+//	h, _ := py.CallFunctionRaw("shapes", "scene")
+//	defer h.Close()
+//	scene, _, err := py.ConvertDeepOrHandle(h)
+//	defer gopython.CloseAll(scene)
+func (py *PureGoPython) ConvertDeepOrHandle(h *PyHandle) (interface{}, []*PyHandle, error) {
+	if !py.IsInitialized() {
+		return nil, nil, ErrNotInitialized
+	}
+	if err := checkHandle(h); err != nil {
+		return nil, nil, err
+	}
+
+	var handles []*PyHandle
+	result, err := py.withGILReturn(func() (interface{}, error) {
+		opts := py.decode
+		return py.deepOrHandle(PyObject(h.obj), &opts, 0, &handles)
+	})
+	if err != nil {
+		py.withGIL(func() error {
+			for _, embedded := range handles {
+				embedded.drop()
+			}
+			return nil
+		})
+		return nil, nil, err
+	}
+	return result, handles, nil
+}
+
+// deepOrHandle converts obj for ConvertDeepOrHandle without GIL management,
+// appending the handles it creates to handles
+func (py *PureGoPython) deepOrHandle(obj PyObject, opts *decodeOptions, depth int, handles *[]*PyHandle) (interface{}, error) {
+	if py.isNone(obj) {
+		return nil, nil
+	}
+	if err := py.checkConversionDepth(depth); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case py.isList(obj), py.isTuple(obj), py.isSet(obj):
+		iterator := py.pyObjectGetIter(uintptr(obj))
+		if iterator == 0 {
+			return nil, fmt.Errorf("object is not iterable: %w", py.getPythonError())
+		}
+		defer py.safeDecRef(iterator)
+
+		items := []interface{}{}
+		for {
+			// PyIter_Next returns a new reference, or NULL when exhausted
+			item := py.pyIterNext(iterator)
+			if item == 0 {
+				break
+			}
+			value, err := py.deepOrHandle(PyObject(item), opts, depth+1, handles)
+			py.safeDecRef(item)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, value)
+		}
+		if py.pyErrOccurred() != 0 {
+			return nil, fmt.Errorf("iteration failed: %w", py.getPythonError())
+		}
+		return items, nil
+
+	case py.isDict(obj):
+		return py.deepOrHandleDict(obj, opts, depth, handles)
+	}
+
+	value, err := py.pythonToGoDepth(obj, opts, depth)
+	if err == nil {
+		return value, nil
+	}
+	if errors.Is(err, ErrMaxDepthExceeded) {
+		return nil, err
+	}
+	if py.pyErrOccurred() != 0 {
+		py.pyErrClear()
+	}
+	return py.embedHandle(uintptr(obj), handles), nil
+}
+
+// embedHandle returns a new handle to the borrowed reference obj and records
+// it in handles
+func (py *PureGoPython) embedHandle(obj uintptr, handles *[]*PyHandle) *PyHandle {
+	py.pyIncRef(obj)
+	h := py.newHandle(obj)
+	*handles = append(*handles, h)
+	return h
+}
+
+// deepOrHandleDict converts a dict for deepOrHandle. Keys that are not
+// strings make the result a map[interface{}]interface{}; keys that can't be
+// converted to a comparable Go value are embedded as handles.
+func (py *PureGoPython) deepOrHandleDict(obj PyObject, opts *decodeOptions, depth int, handles *[]*PyHandle) (interface{}, error) {
+	keys := py.pyDictKeys(uintptr(obj))
+	if keys == 0 {
+		return nil, fmt.Errorf("failed to get dict keys")
+	}
+	defer py.safeDecRef(keys)
+
+	size := py.pyListSize(keys)
+	ordered := make(OrderedDictValue, 0, size)
+	anyKeys := make(map[interface{}]interface{}, size)
+	stringKeys := true
+	for i := 0; i < size; i++ {
+		// PyList_GetItem and PyDict_GetItem return borrowed references
+		keyObj := py.pyListGetItem(keys, i)
+		key, err := py.pythonToGoDepth(PyObject(keyObj), opts, depth+1)
+		if errors.Is(err, ErrMaxDepthExceeded) {
+			return nil, err
+		}
+		if _, isBig := key.(*big.Int); err != nil || isBig || (key != nil && !reflect.TypeOf(key).Comparable()) {
+			if py.pyErrOccurred() != 0 {
+				py.pyErrClear()
+			}
+			key = py.embedHandle(keyObj, handles)
+		}
+
+		valObj := py.pyDictGetItem(uintptr(obj), keyObj)
+		if valObj == 0 {
+			continue
+		}
+		// Hold the borrowed value in case converting it modifies the dict
+		py.pyIncRef(valObj)
+		value, err := py.deepOrHandle(PyObject(valObj), opts, depth+1, handles)
+		py.safeDecRef(valObj)
+		if err != nil {
+			return nil, err
+		}
+
+		if s, ok := key.(string); ok {
+			ordered = append(ordered, KeyValue{Key: s, Value: value})
+		} else {
+			stringKeys = false
+		}
+		anyKeys[key] = value
+	}
+
+	if !stringKeys {
+		return anyKeys, nil
+	}
+	if opts.preserveDictOrder {
+		return ordered, nil
+	}
+	result := make(map[string]interface{}, len(ordered))
+	for _, kv := range ordered {
+		result[kv.Key] = kv.Value
+	}
+	return result, nil
+}
@@ -3,13 +3,52 @@ package gopython
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unsafe"
 
 	"github.com/ebitengine/purego"
 )
 
-// NewPureGoPython creates a new Python runtime instance
+// ErrNotInitialized is returned by calls made before Initialize, after
+// Finalize or after Close
+var ErrNotInitialized = errors.New("Python interpreter is not initialized")
+
+// ErrClosed is returned by settings and initialization once Close has
+// unloaded libpython, and by calls that were waiting for the interpreter when
+// it was closed
+var ErrClosed = errors.New("Python runtime is closed")
+
+// ErrFeatureUnavailable is returned when a feature needs a libpython function
+// the loaded library doesn't export. See MissingSymbols.
+var ErrFeatureUnavailable = errors.New("feature unavailable")
+
+// ErrModuleNotFound is wrapped by call errors when the module being called
+// into does not exist. Other import failures, such as an exception raised
+// while the module runs or a missing dependency of it, don't wrap it.
+var ErrModuleNotFound = errors.New("module not found")
+
+// ErrFunctionNotFound is wrapped by call errors when the module was imported
+// but has no attribute with the requested name
+var ErrFunctionNotFound = errors.New("function not found")
+
+// NewPureGoPython creates a new Python runtime instance. If libpythonPath is
+// empty the library is located with DiscoverLibPython.
 func NewPureGoPython(libpythonPath string) (*PureGoPython, error) {
+	if libpythonPath == "" {
+		discovered, err := DiscoverLibPython()
+		if err != nil {
+			return nil, err
+		}
+		libpythonPath = discovered
+	}
+
 	// Validate library path for current platform
 	if err := ValidateLibraryPath(libpythonPath); err != nil {
 		return nil, fmt.Errorf("invalid library path: %v", err)
@@ -38,42 +77,197 @@ func NewPureGoPython(libpythonPath string) (*PureGoPython, error) {
 	return py, nil
 }
 
-
 // Initialize initializes the Python interpreter with default system configuration
 func (py *PureGoPython) Initialize() error {
+	if py.isClosed() {
+		return ErrClosed
+	}
+	if py.pyInitialize == nil {
+		return errors.New("Python functions not registered")
+	}
+
+	py.pyInitialize()
+	py.finalized = false
+	py.releaseMainThread()
+	return nil
+}
+
+// InitializeWithConfig initializes the Python interpreter with the startup
+// options in cfg, the equivalent of python command line flags such as -I,
+// -S, -O and -B. Isolated mode keeps the host user's PYTHONPATH, PYTHONHOME
+// and user site-packages out of the embedded interpreter, for reproducible
+// behavior across machines.
+//
+// The options are set through the legacy global configuration variables
+// (Py_IsolatedFlag and friends), which Py_Initialize still reads in Python
+// 3.10 and 3.11, and are restored to their previous values afterwards.
+//
+// Example:
+//
+//	err := py.InitializeWithConfig(gopython.InitConfig{Isolated: true, DontWriteBytecode: true})
+func (py *PureGoPython) InitializeWithConfig(cfg InitConfig) error {
+	if py.isClosed() {
+		return ErrClosed
+	}
 	if py.pyInitialize == nil {
 		return errors.New("Python functions not registered")
 	}
+	if cfg.OptimizeLevel < 0 || cfg.OptimizeLevel > 2 {
+		return fmt.Errorf("optimize level must be 0, 1 or 2, got %d", cfg.OptimizeLevel)
+	}
+
+	if cfg.ProgramName != "" {
+		if err := py.requireFeature("path configuration"); err != nil {
+			return err
+		}
+	}
+
+	flags := []struct {
+		name  string
+		value int32
+	}{
+		{"Py_NoSiteFlag", boolFlag(cfg.NoSite)},
+		{"Py_OptimizeFlag", int32(cfg.OptimizeLevel)},
+		{"Py_DontWriteBytecodeFlag", boolFlag(cfg.DontWriteBytecode)},
+		// -I implies -E and -s
+		{"Py_IsolatedFlag", boolFlag(cfg.Isolated)},
+		{"Py_IgnoreEnvironmentFlag", boolFlag(cfg.Isolated)},
+		{"Py_NoUserSiteDirectory", boolFlag(cfg.Isolated)},
+	}
+	for _, flag := range flags {
+		if flag.value == 0 {
+			continue
+		}
+		restore, err := py.setGlobalFlag(flag.name, flag.value)
+		if err != nil {
+			return err
+		}
+		defer restore()
+	}
+	if cfg.ProgramName != "" {
+		// Py_SetProgramName copies the string
+		py.pySetProgramName(stringToWideCString(cfg.ProgramName))
+	}
 
 	py.pyInitialize()
+	py.finalized = false
+	py.releaseMainThread()
 	return nil
 }
 
+// setGlobalFlag sets one of libpython's legacy int configuration variables,
+// such as Py_NoSiteFlag, and returns the function restoring its previous
+// value. These variables are only read during initialization.
+func (py *PureGoPython) setGlobalFlag(name string, value int32) (func(), error) {
+	addr := py.lookupDataSymbol(name)
+	if addr == 0 {
+		return nil, fmt.Errorf("%s not found", name)
+	}
+	flag := *(**int32)(unsafe.Pointer(&addr))
+	previous := *flag
+	*flag = value
+	return func() { *flag = previous }, nil
+}
+
+// boolFlag converts a bool option to the 0/1 value of a configuration flag
+func boolFlag(enabled bool) int32 {
+	if enabled {
+		return 1
+	}
+	return 0
+}
+
 // IsInitialized returns true if the Python interpreter is initialized
 func (py *PureGoPython) IsInitialized() bool {
-	if py.pyIsInitialized == nil {
+	py.closeMu.RLock()
+	defer py.closeMu.RUnlock()
+	if py.closed || py.pyIsInitialized == nil {
 		return false
 	}
 	return py.pyIsInitialized() != 0
 }
 
-// Finalize shuts down the Python interpreter
+// Ping checks that the interpreter is responsive by evaluating 1+1 and
+// confirming the result is 2, for use in health checks and liveness probes.
+// It waits for the interpreter like any other call, so a probe should apply
+// its own timeout to catch an interpreter that never becomes available.
+func (py *PureGoPython) Ping() error {
+	if !py.IsInitialized() {
+		return ErrNotInitialized
+	}
+
+	return py.withGIL(func() error {
+		globals := py.pyDictNew()
+		if globals == 0 {
+			return errors.New("ping failed: cannot create a dict")
+		}
+		defer py.safeDecRef(globals)
+
+		resultObj := py.pyRunStringFlags(stringToCString("1+1"), pyEvalInput, globals, globals, 0)
+		if resultObj == 0 {
+			return fmt.Errorf("ping failed: %w", py.getPythonError())
+		}
+		defer py.safeDecRef(resultObj)
+
+		result, err := py.internalToGo(PyObject(resultObj))
+		if err != nil {
+			return fmt.Errorf("ping failed: %v", err)
+		}
+		if result != int64(2) {
+			return fmt.Errorf("ping failed: 1+1 evaluated to %v", result)
+		}
+		return nil
+	})
+}
+
+// Finalize shuts down the Python interpreter, releasing cached modules,
+// prepared calls and redirected streams first. Calling it again after a
+// successful or failed finalization does nothing. Use Close to also unload
+// libpython.
 func (py *PureGoPython) Finalize() error {
+	if py.isClosed() {
+		// Close finalizes before unloading libpython
+		return nil
+	}
 	if py.pyFinalizeEx == nil {
 		return errors.New("Python functions not registered")
 	}
 
+	unlock, err := py.lock()
+	if err != nil {
+		return nil
+	}
+	defer unlock()
+
+	// Finalizing twice is harmless, so Finalize can be deferred alongside an
+	// explicit shutdown path
+	if py.finalized {
+		return nil
+	}
 	if !py.IsInitialized() {
-		return errors.New("Python interpreter is not initialized")
+		return ErrNotInitialized
 	}
+	py.finalized = true
 
 	// Try to clean up any remaining Python objects and threads
 	py.withGIL(func() error {
+		py.restoreStreams()
+		py.endPools()
+		py.clearCallCache()
+
 		cleanupCode := `
 import gc
 import threading
 import sys
 
+# Close the event loop used by RunCoroutine
+loop = getattr(sys, '_gopython_event_loop', None)
+if loop is not None and not loop.is_closed() and not loop.is_running():
+    try:
+        loop.close()
+    except:
+        pass
+
 # Force garbage collection
 gc.collect()
 
@@ -105,6 +299,8 @@ gc.collect()
 		return nil
 	})
 
+	// Py_FinalizeEx must run with the main thread state current
+	py.restoreMainThread()
 	result := py.pyFinalizeEx()
 	if result < 0 {
 		return fmt.Errorf("Python interpreter finalization failed with code: %d", result)
@@ -113,14 +309,136 @@ gc.collect()
 	return nil
 }
 
+// Close finalizes the interpreter if it is still initialized and unloads
+// libpython, releasing the library mapping. It waits for calls in progress.
+// Every later call on the runtime returns ErrNotInitialized, and settings and
+// initialization return ErrClosed. Close is terminal: libpython generally
+// cannot be initialized again safely once finalized, so create a new runtime
+// with NewPureGoPython only if the library supports it. If finalization
+// fails, libpython stays loaded, since Python threads may still be running
+// in it. Calling Close more than once is safe.
+func (py *PureGoPython) Close() error {
+	if py.isClosed() {
+		return nil
+	}
+
+	var finalizeErr error
+	if py.IsInitialized() {
+		finalizeErr = py.Finalize()
+	}
+
+	unlock, err := py.lock()
+	if err != nil {
+		return nil // Closed concurrently
+	}
+	defer unlock()
+
+	releaseCallbackOwner(py)
+
+	// Calls waiting for the lock, and any later call, see the flag before
+	// reaching into the library
+	py.closeMu.Lock()
+	py.closed = true
+	py.closeMu.Unlock()
+
+	if finalizeErr != nil {
+		return fmt.Errorf("failed to finalize Python, libpython stays loaded: %v", finalizeErr)
+	}
+	if err := purego.Dlclose(py.libHandle); err != nil {
+		return fmt.Errorf("failed to unload libpython: %v", err)
+	}
+	return nil
+}
+
+// Version returns the version of the running interpreter from
+// sys.version_info
+func (py *PureGoPython) Version() (major, minor, micro int, err error) {
+	if !py.IsInitialized() {
+		return 0, 0, 0, ErrNotInitialized
+	}
+
+	err = py.withGIL(func() error {
+		sysModule, err := py.importModule("sys")
+		if err != nil {
+			return err
+		}
+		defer py.safeDecRef(sysModule)
+
+		versionInfo := py.getAttrString(sysModule, "version_info")
+		if versionInfo == 0 {
+			return errors.New("sys.version_info is not set")
+		}
+		defer py.safeDecRef(versionInfo)
+
+		parts, err := py.intAttrs(PyObject(versionInfo), "sys.version_info", "major", "minor", "micro")
+		if err != nil {
+			return err
+		}
+		major, minor, micro = int(parts[0]), int(parts[1]), int(parts[2])
+		return nil
+	})
+	return major, minor, micro, err
+}
+
+// VersionString returns sys.version, the full version and build information
+// of the running interpreter
+func (py *PureGoPython) VersionString() (string, error) {
+	if !py.IsInitialized() {
+		return "", ErrNotInitialized
+	}
+
+	result, err := py.withGILReturn(func() (interface{}, error) {
+		sysModule, err := py.importModule("sys")
+		if err != nil {
+			return nil, err
+		}
+		defer py.safeDecRef(sysModule)
+
+		version := py.getAttrString(sysModule, "version")
+		if version == 0 {
+			return nil, errors.New("sys.version is not set")
+		}
+		defer py.safeDecRef(version)
+		return py.pythonStringToGo(PyObject(version))
+	})
+	if err != nil {
+		return "", err
+	}
+	return result.(string), nil
+}
+
+// VerifyVersion returns an error unless the running interpreter is Python
+// expectedMajor.expectedMinor. Call it right after initialization to fail
+// fast when the wrong libpython was loaded.
+//
+// Example:
+//
+//	if err := py.VerifyVersion(3, 10); err != nil {
+//	    log.Fatal(err)
+//	}
+func (py *PureGoPython) VerifyVersion(expectedMajor, expectedMinor int) error {
+	major, minor, micro, err := py.Version()
+	if err != nil {
+		return fmt.Errorf("failed to read Python version: %w", err)
+	}
+	if major != expectedMajor || minor != expectedMinor {
+		return fmt.Errorf("expected Python %d.%d, but the loaded library is Python %d.%d.%d", expectedMajor, expectedMinor, major, minor, micro)
+	}
+	return nil
+}
+
 // RunString executes Python code from a string
 func (py *PureGoPython) RunString(code string) error {
 	if !py.IsInitialized() {
-		return errors.New("Python interpreter is not initialized")
+		return ErrNotInitialized
+	}
+
+	cCode, err := codeToCString(code)
+	if err != nil {
+		return err
 	}
 
 	return py.withGIL(func() error {
-		cCode := stringToCString(code)
 		result := py.pyRunSimpleString(cCode)
 		if result != 0 {
 			return py.getPythonError()
@@ -129,142 +447,1642 @@ func (py *PureGoPython) RunString(code string) error {
 	})
 }
 
-// RunFile executes Python code from a file
-func (py *PureGoPython) RunFile(filename string) error {
+// mainDict returns the __main__ module's namespace as a borrowed reference
+func (py *PureGoPython) mainDict() (uintptr, error) {
+	// PyImport_AddModule and PyModule_GetDict return borrowed references
+	module := py.pyImportAddModule(stringToCString("__main__"))
+	if module == 0 {
+		return 0, fmt.Errorf("failed to get __main__ module: %w", py.getPythonError())
+	}
+	return py.pyModuleGetDict(module), nil
+}
+
+// GetGlobal returns the value of a global variable in __main__, the
+// namespace RunString executes in, converted to Go
+//
+// Example:
+//
+//	py.SetGlobal("radius", 2.0)
+//	py.RunString("area = 3.14159 * radius ** 2")
+//	area, err := py.GetGlobal("area")
+func (py *PureGoPython) GetGlobal(name string) (interface{}, error) {
 	if !py.IsInitialized() {
-		return errors.New("Python interpreter is not initialized")
+		return nil, ErrNotInitialized
 	}
 
-	// Check if file exists
-	if _, err := os.Stat(filename); os.IsNotExist(err) {
-		return fmt.Errorf("file does not exist: %s", filename)
+	return py.withGILReturn(func() (interface{}, error) {
+		globals, err := py.mainDict()
+		if err != nil {
+			return nil, err
+		}
+
+		// PyDict_GetItemString returns a borrowed reference. Hold it while
+		// converting, since conversion may run code that rebinds the global.
+		value := py.pyDictGetItemString(globals, stringToCString(name))
+		if value == 0 {
+			return nil, fmt.Errorf("global '%s' is not defined", name)
+		}
+		py.pyIncRef(value)
+		defer py.safeDecRef(value)
+		result, err := py.pythonToGo(PyObject(value))
+		if err != nil && !isPartial(err) {
+			return nil, fmt.Errorf("failed to convert global '%s': %w", name, err)
+		}
+		return result, err
+	})
+}
+
+// SetGlobal sets a global variable in __main__ to value converted to Python,
+// making it visible to code run with RunString
+func (py *PureGoPython) SetGlobal(name string, value interface{}) error {
+	if !py.IsInitialized() {
+		return ErrNotInitialized
+	}
+	if name == "" {
+		return errors.New("global name cannot be empty")
 	}
 
-	// Read file content and execute as string (simpler and more reliable)
-	content, err := os.ReadFile(filename)
-	if err != nil {
-		return fmt.Errorf("failed to read file %s: %v", filename, err)
+	return py.withGIL(func() error {
+		globals, err := py.mainDict()
+		if err != nil {
+			return err
+		}
+
+		pyValue, err := py.goToPython(value)
+		if err != nil {
+			return fmt.Errorf("failed to convert value for global '%s': %w", name, err)
+		}
+		defer py.safeDecRef(uintptr(pyValue))
+
+		// PyDict_SetItemString doesn't steal the reference
+		if py.pyDictSetItemString(globals, stringToCString(name), uintptr(pyValue)) != 0 {
+			return fmt.Errorf("failed to set global '%s': %w", name, py.getPythonError())
+		}
+		return nil
+	})
+}
+
+// SetGlobals sets several global variables of module in one locked
+// operation, e.g. to seed a script's configuration. Use "__main__" for the
+// namespace RunString executes in; other modules are imported first. All
+// values are converted before any is set, so a conversion error leaves the
+// namespace unchanged.
+//
+// Example:
+//
+//	err := py.SetGlobals("__main__", map[string]interface{}{
+//	    "batch_size": 32,
+//	    "model_name": "resnet",
+//	})
+func (py *PureGoPython) SetGlobals(module string, values map[string]interface{}) error {
+	if !py.IsInitialized() {
+		return ErrNotInitialized
 	}
 
-	return py.RunString(string(content))
+	return py.withGIL(func() error {
+		var globals uintptr
+		if module == "__main__" {
+			var err error
+			if globals, err = py.mainDict(); err != nil {
+				return err
+			}
+		} else {
+			moduleObj, err := py.importModule(module)
+			if err != nil {
+				return err
+			}
+			defer py.safeDecRef(moduleObj)
+			// PyModule_GetDict returns a borrowed reference
+			globals = py.pyModuleGetDict(moduleObj)
+		}
+
+		pyValues := make(map[string]uintptr, len(values))
+		defer func() {
+			for _, pyValue := range pyValues {
+				py.safeDecRef(pyValue)
+			}
+		}()
+		for name, value := range values {
+			if name == "" {
+				return errors.New("global name cannot be empty")
+			}
+			pyValue, err := py.goToPython(value)
+			if err != nil {
+				return fmt.Errorf("failed to convert value for global '%s': %w", name, err)
+			}
+			pyValues[name] = uintptr(pyValue)
+		}
+
+		for name, pyValue := range pyValues {
+			// PyDict_SetItemString doesn't steal the reference
+			if py.pyDictSetItemString(globals, stringToCString(name), pyValue) != 0 {
+				return fmt.Errorf("failed to set global '%s': %w", name, py.getPythonError())
+			}
+		}
+		return nil
+	})
 }
 
-// CallFunction calls a Python function with the given arguments
-func (py *PureGoPython) CallFunction(module, function string, args ...interface{}) (interface{}, error) {
+// GetModuleAttr imports module and returns its attribute name converted to a
+// Go value, without calling it. Use it for constants, version strings and
+// other data attributes that CallFunction can't read.
+//
+// Example:
+//
+//	pi, err := py.GetModuleAttr("math", "pi")
+func (py *PureGoPython) GetModuleAttr(module, name string) (interface{}, error) {
 	if !py.IsInitialized() {
-		return nil, errors.New("Python interpreter is not initialized")
+		return nil, ErrNotInitialized
 	}
 
 	return py.withGILReturn(func() (interface{}, error) {
-		return py.callFunctionUnsafe(module, function, args...)
+		moduleObj, err := py.cachedModule(module)
+		if err != nil {
+			return nil, err
+		}
+		defer py.safeDecRef(moduleObj)
+
+		attr := py.pyObjectGetAttrString(moduleObj, stringToCString(name))
+		if attr == 0 {
+			return nil, fmt.Errorf("failed to get '%s.%s': %w", module, name, py.getPythonError())
+		}
+		defer py.safeDecRef(attr)
+
+		result, err := py.pythonToGo(PyObject(attr))
+		if err != nil && !isPartial(err) {
+			return nil, fmt.Errorf("failed to convert '%s.%s': %w", module, name, err)
+		}
+		return result, err
 	})
 }
 
-// callFunctionUnsafe performs the actual function call without GIL management
-func (py *PureGoPython) callFunctionUnsafe(module, function string, args ...interface{}) (interface{}, error) {
-	// Import the module
-	moduleNameObj, err := py.goToPython(module)
-	if err != nil {
-		return nil, fmt.Errorf("failed to convert module name: %v", err)
+// RecursionLimit returns the interpreter's current recursion limit, as
+// reported by sys.getrecursionlimit.
+func (py *PureGoPython) RecursionLimit() (int, error) {
+	if !py.IsInitialized() {
+		return 0, ErrNotInitialized
 	}
-	defer py.safeDecRef(uintptr(moduleNameObj))
 
-	moduleObj := py.pyImportImport(uintptr(moduleNameObj))
-	if moduleObj == 0 {
-		return nil, fmt.Errorf("failed to import module '%s': %v", module, py.getPythonError())
+	result, err := py.withGILReturn(func() (interface{}, error) {
+		return py.callFunctionUnsafe("sys", "getrecursionlimit")
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get recursion limit: %w", err)
 	}
-	defer py.safeDecRef(moduleObj)
-
-	// Get the function from the module
-	functionNameObj, err := py.goToPython(function)
+	limit, err := toPlatformInt(result)
 	if err != nil {
-		return nil, fmt.Errorf("failed to convert function name: %v", err)
+		return 0, fmt.Errorf("failed to get recursion limit: %v", err)
 	}
-	defer py.safeDecRef(uintptr(functionNameObj))
+	return limit, nil
+}
 
-	functionObj := py.pyObjectGetAttr(moduleObj, uintptr(functionNameObj))
-	if functionObj == 0 {
-		return nil, fmt.Errorf("function '%s' not found in module '%s'", function, module)
+// SetRecursionLimit sets the interpreter's recursion limit with
+// sys.setrecursionlimit. Pair it with RecursionLimit to restore the previous
+// value after a deeply recursive workload:
+//
+//	previous, _ := py.RecursionLimit()
+//	py.SetRecursionLimit(5000)
+//	defer py.SetRecursionLimit(previous)
+//
+// Python rejects limits below 1 and limits lower than the current recursion
+// depth.
+func (py *PureGoPython) SetRecursionLimit(limit int) error {
+	if !py.IsInitialized() {
+		return ErrNotInitialized
 	}
-	defer py.safeDecRef(functionObj)
 
-	// Build argument tuple
-	argTuple, err := py.buildArgumentTuple(args...)
+	_, err := py.withGILReturn(func() (interface{}, error) {
+		return py.callFunctionUnsafe("sys", "setrecursionlimit", limit)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to build arguments: %v", err)
-	}
-	defer py.safeDecRef(uintptr(argTuple))
-
-	// Call the function
-	resultObj := py.pyObjectCallObject(functionObj, uintptr(argTuple))
-	if resultObj == 0 {
-		return nil, fmt.Errorf("function call failed: %v", py.getPythonError())
+		return fmt.Errorf("failed to set recursion limit to %d: %w", limit, err)
 	}
-	defer py.safeDecRef(resultObj)
+	return nil
+}
 
-	// Convert result to Go
-	return py.pythonToGo(PyObject(resultObj))
+// SyntaxError describes Python source that failed to compile
+type SyntaxError struct {
+	Message string // Compiler message, e.g. "invalid syntax"
+	Line    int    // 1-based line number, 0 if unknown
+	Offset  int    // 1-based column offset within the line, 0 if unknown
+	Text    string // Source line containing the error, if available
 }
 
-// CallPyFunction calls a Python function with type-safe generics for request and response types
-func CallPyFunction[TRequest, TResponse any](py *PureGoPython, module, function string, request TRequest) (TResponse, error) {
-	var zero TResponse
+// Error implements the error interface
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at line %d, offset %d: %s", e.Line, e.Offset, e.Message)
+}
 
+// CheckSyntax compiles code without executing it. It returns nil if the code
+// is valid Python and a *SyntaxError with the position of the problem
+// otherwise (IndentationError and TabError included).
+func (py *PureGoPython) CheckSyntax(code string) error {
 	if !py.IsInitialized() {
-		return zero, errors.New("Python interpreter is not initialized")
+		return ErrNotInitialized
 	}
 
-	// Call the underlying CallFunction with the request
-	result, err := py.CallFunction(module, function, request)
-	if err != nil {
-		return zero, err
-	}
-
-	// Try to convert the result to the expected response type
-	response, ok := result.(TResponse)
-	if !ok {
-		return zero, fmt.Errorf("failed to convert result to %T: got %T", zero, result)
+	if line, offset := findNUL(code); line != 0 {
+		return &SyntaxError{
+			Message: "source code cannot contain null bytes",
+			Line:    line,
+			Offset:  offset,
+			Text:    strings.Split(code, "\n")[line-1],
+		}
 	}
 
-	return response, nil
+	return py.withGIL(func() error {
+		codeObj := py.pyCompileString(stringToCString(code), stringToCString("<string>"), pyFileInput)
+		if codeObj != 0 {
+			py.safeDecRef(codeObj)
+			return nil
+		}
+		return py.fetchSyntaxError()
+	})
 }
 
-// getPythonError extracts Python error information
-func (py *PureGoPython) getPythonError() error {
+// fetchSyntaxError converts the pending Python exception to a *SyntaxError,
+// or to a plain error if it is not a syntax error
+func (py *PureGoPython) fetchSyntaxError() error {
 	if py.pyErrOccurred() == 0 {
 		return errors.New("unknown Python error")
 	}
 
 	var ptype, pvalue, ptraceback uintptr
 	py.pyErrFetch(&ptype, &pvalue, &ptraceback)
+	py.pyErrNormalizeException(&ptype, &pvalue, &ptraceback)
+	defer py.safeDecRef(ptype)
+	defer py.safeDecRef(pvalue)
+	defer py.safeDecRef(ptraceback)
 
-	// Clear the error state
-	py.pyErrClear()
+	if pvalue == 0 || py.pyObjectHasAttrString(pvalue, stringToCString("lineno")) == 0 {
+		message := "Python error"
+		if pvalue != 0 {
+			if str, err := py.objectToString(pvalue); err == nil {
+				message = str
+			}
+		}
+		return fmt.Errorf("Python error: %s", message)
+	}
 
-	if pvalue == 0 {
-		return errors.New("Python error occurred but no error message available")
+	syntaxErr := &SyntaxError{}
+	if msg, ok := py.attrValue(pvalue, "msg").(string); ok {
+		syntaxErr.Message = msg
+	}
+	if line, ok := py.attrValue(pvalue, "lineno").(int64); ok {
+		syntaxErr.Line = int(line)
 	}
+	if offset, ok := py.attrValue(pvalue, "offset").(int64); ok {
+		syntaxErr.Offset = int(offset)
+	}
+	if text, ok := py.attrValue(pvalue, "text").(string); ok {
+		syntaxErr.Text = strings.TrimRight(text, "\r\n")
+	}
+	return syntaxErr
+}
 
-	// Convert error to string
-	errorStr := py.pyObjectStr(pvalue)
-	if errorStr == 0 {
-		py.safeDecRef(ptype)
-		py.safeDecRef(pvalue)
-		py.safeDecRef(ptraceback)
-		return errors.New("Python error occurred but failed to get error string")
+// attrValue returns the named attribute of obj converted to Go, or nil if it
+// is missing or cannot be converted
+func (py *PureGoPython) attrValue(obj uintptr, name string) interface{} {
+	attr := py.getAttrString(obj, name)
+	if attr == 0 {
+		return nil
+	}
+	defer py.safeDecRef(attr)
+
+	value, err := py.internalToGo(PyObject(attr))
+	if err != nil {
+		py.pyErrClear()
+		return nil
+	}
+	return value
+}
+
+// isolatedModuleCounter provides unique names for RunIsolated modules
+var isolatedModuleCounter int64
+
+// RunIsolated executes code in a brand-new module instead of __main__ and
+// returns the names it defined, converted to Go values. Names starting with
+// "__" and values that cannot be converted (functions, classes, modules) are
+// omitted. The module is removed from sys.modules afterwards, so scripts run
+// this way neither pollute __main__ nor see each other's state.
+func (py *PureGoPython) RunIsolated(code string) (map[string]interface{}, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+	cCode, err := codeToCString(code)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := py.withGILReturn(func() (interface{}, error) {
+		name := fmt.Sprintf("__gopython_isolated_%d", atomic.AddInt64(&isolatedModuleCounter, 1))
+		cName := stringToCString(name)
+
+		// PyImport_AddModule returns a borrowed reference owned by sys.modules
+		module := py.pyImportAddModule(cName)
+		if module == 0 {
+			return nil, fmt.Errorf("failed to create isolated module: %w", py.getPythonError())
+		}
+		defer func() {
+			if py.pyDictDelItemString(py.pyImportGetModuleDict(), cName) != 0 {
+				py.pyErrClear()
+			}
+		}()
+
+		globals := py.pyModuleGetDict(module)
+		builtins, err := py.importModule("builtins")
+		if err != nil {
+			return nil, err
+		}
+		defer py.safeDecRef(builtins)
+		if py.pyDictSetItemString(globals, stringToCString("__builtins__"), builtins) != 0 {
+			return nil, fmt.Errorf("failed to seed builtins: %w", py.getPythonError())
+		}
+
+		resultObj := py.pyRunStringFlags(cCode, pyFileInput, globals, globals, 0)
+		if resultObj == 0 {
+			return nil, py.getPythonError()
+		}
+		py.safeDecRef(resultObj)
+
+		return py.namespaceToMap(globals), nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return result.(map[string]interface{}), nil
+}
+
+// moduleHelper creates a module from source and registers it in sys.modules,
+// leaving any previous module in place if the source fails to compile or run
+const moduleHelper = `
+import sys
+import types
+
+def define_module(name, source):
+    filename = "<" + name + ">"
+    code = compile(source, filename, "exec")
+    module = types.ModuleType(name)
+    module.__file__ = filename
+    previous = sys.modules.get(name)
+    sys.modules[name] = module
+    try:
+        exec(code, module.__dict__)
+    except BaseException:
+        if previous is None:
+            sys.modules.pop(name, None)
+        else:
+            sys.modules[name] = previous
+        raise
+    parent, _, child = name.rpartition(".")
+    if parent and parent in sys.modules:
+        setattr(sys.modules[parent], child, module)
+`
 
-	cStr := py.pyUnicodeAsUTF8(errorStr)
-	errorMessage := "Python error"
-	if cStr != nil {
-		errorMessage = cStringToGoString(cStr)
+// DefineModule creates an importable module named name from Python source,
+// so generated code can live in its own namespace instead of __main__ and be
+// called with CallFunction(name, ...) or imported by other Python code.
+// Defining a module that already exists replaces it; prepared calls into the
+// old module must be prepared again. If the source fails to compile or
+// raises, the previous module is kept.
+//
+// Example:
+//
+//	err := py.DefineModule("rules", "def score(x):\n    return x * 2\n")
+//	result, err := py.CallFunction("rules", "score", 21)
+func (py *PureGoPython) DefineModule(name, source string) error {
+	if !py.IsInitialized() {
+		return ErrNotInitialized
+	}
+	if name == "" {
+		return errors.New("module name cannot be empty")
 	}
 
-	// Clean up error objects
-	py.safeDecRef(ptype)
-	py.safeDecRef(pvalue)
-	py.safeDecRef(ptraceback)
-	py.safeDecRef(errorStr)
+	return py.withGIL(func() error {
+		resultObj, err := py.callHelper(moduleHelper, "define_module", name, source)
+		if err != nil {
+			return fmt.Errorf("failed to define module '%s': %w", name, err)
+		}
+		py.safeDecRef(resultObj)
+		py.forgetModule(name)
+		return nil
+	})
+}
+
+// reloadHelper reloads a module that was imported before and lists its
+// loaded submodules, which importlib.reload leaves untouched
+const reloadHelper = `
+import importlib
+import sys
+
+def loaded_submodules(name):
+    prefix = name + "."
+    return sorted(m for m in sys.modules if m.startswith(prefix))
+
+def reload_module(name):
+    module = sys.modules.get(name)
+    if module is None:
+        return False
+    importlib.reload(module)
+    return True
+`
 
-	return fmt.Errorf("Python error: %s", errorMessage)
-}
\ No newline at end of file
+// ReloadModule re-executes the source of a previously imported module with
+// importlib.reload, so edits to its .py file are picked up without
+// restarting the interpreter. Prepared calls into the module must be
+// prepared again. Reloading is shallow: submodules are not reloaded, and
+// objects other modules imported from it (from module import name) keep the
+// old definitions. Errors list the module's loaded submodules, which may
+// still be stale.
+//
+// Example:
+//
+//	py.AddSysPath("./scripts")
+//	for range changes {
+//	    if err := py.ReloadModule("pipeline"); err != nil {
+//	        log.Print(err)
+//	    }
+//	}
+func (py *PureGoPython) ReloadModule(name string) error {
+	if !py.IsInitialized() {
+		return ErrNotInitialized
+	}
+
+	return py.withGIL(func() error {
+		staleNote := ""
+		if submodulesObj, err := py.callHelper(reloadHelper, "loaded_submodules", name); err == nil {
+			if submodules, err := py.internalToGo(PyObject(submodulesObj)); err == nil {
+				if list, ok := submodules.([]interface{}); ok && len(list) > 0 {
+					staleNote = fmt.Sprintf(" (submodules not reloaded, may be stale: %v)", list)
+				}
+			}
+			py.safeDecRef(submodulesObj)
+		}
+
+		resultObj, err := py.callHelper(reloadHelper, "reload_module", name)
+		if err != nil {
+			return fmt.Errorf("failed to reload module '%s'%s: %w", name, staleNote, err)
+		}
+		defer py.safeDecRef(resultObj)
+		if reloaded, _ := py.internalToGo(PyObject(resultObj)); reloaded != true {
+			return fmt.Errorf("module '%s' has not been imported%s", name, staleNote)
+		}
+
+		py.forgetModule(name)
+		return nil
+	})
+}
+
+// unloadHelper lists the loaded modules and removes the ones missing from a
+// snapshot, detaching submodules from their parent packages so the package
+// doesn't keep them alive
+const unloadHelper = `
+import gc
+import importlib
+import sys
+
+_PINNED = ("__main__", "builtins", "sys")
+
+def _gopython_module_names():
+    return [name for name in sys.modules if isinstance(name, str)]
+
+def _gopython_unload_since(names):
+    keep = set(names)
+    removed = [name for name in list(sys.modules)
+               if isinstance(name, str) and name not in keep and name not in _PINNED]
+    for name in removed:
+        module = sys.modules.pop(name, None)
+        parent, _, child = name.rpartition(".")
+        if parent and getattr(sys.modules.get(parent), child, None) is module:
+            try:
+                delattr(sys.modules[parent], child)
+            except AttributeError:
+                pass
+    importlib.invalidate_caches()
+    gc.collect()
+    return removed
+`
+
+// ModuleSnapshot returns the names of the modules currently in sys.modules,
+// each mapped to true. Pass it to UnloadModulesSince to unload what was
+// imported afterwards. It returns nil if the interpreter is not initialized
+// or sys.modules can't be read.
+func (py *PureGoPython) ModuleSnapshot() map[string]bool {
+	if !py.IsInitialized() {
+		return nil
+	}
+
+	result, err := py.withGILReturn(func() (interface{}, error) {
+		namesObj, err := py.callHelper(unloadHelper, "_gopython_module_names")
+		if err != nil {
+			return nil, err
+		}
+		defer py.safeDecRef(namesObj)
+		return py.internalToGo(PyObject(namesObj))
+	})
+	if err != nil {
+		return nil
+	}
+
+	names := result.([]interface{})
+	snapshot := make(map[string]bool, len(names))
+	for _, name := range names {
+		snapshot[name.(string)] = true
+	}
+	return snapshot
+}
+
+// UnloadModulesSince removes every module from sys.modules that is not
+// marked true in snapshot, typically everything a plugin imported since
+// ModuleSnapshot was taken, so the next plugin starts from the same set of
+// modules and the unloaded ones can be garbage collected. Prepared calls into
+// unloaded modules must be prepared again. __main__, builtins and sys are
+// never unloaded.
+//
+// Unloading only drops the interpreter's references. A module stays in memory
+// while anything else refers to it or to its objects, such as a handle, a
+// module that imported names from it, or a running thread. C extensions are
+// never truly unloaded: the shared library stays mapped and some extensions
+// keep global state, so importing one again may not reinitialize it.
+//
+// Example:
+//
+//	before := py.ModuleSnapshot()
+//	_, err := py.CallFunction("plugin", "run", job)
+//	py.UnloadModulesSince(before)
+func (py *PureGoPython) UnloadModulesSince(snapshot map[string]bool) error {
+	if !py.IsInitialized() {
+		return ErrNotInitialized
+	}
+	if len(snapshot) == 0 {
+		return errors.New("snapshot cannot be empty")
+	}
+
+	keep := make([]interface{}, 0, len(snapshot))
+	for name, loaded := range snapshot {
+		if loaded {
+			keep = append(keep, name)
+		}
+	}
+
+	return py.withGIL(func() error {
+		removedObj, err := py.callHelper(unloadHelper, "_gopython_unload_since", keep)
+		if err != nil {
+			return fmt.Errorf("failed to unload modules: %w", err)
+		}
+		defer py.safeDecRef(removedObj)
+
+		removed, err := py.internalToGo(PyObject(removedObj))
+		if err != nil {
+			return fmt.Errorf("failed to list unloaded modules: %v", err)
+		}
+		for _, name := range removed.([]interface{}) {
+			py.forgetModule(name.(string))
+		}
+		return nil
+	})
+}
+
+// defineHelper executes code in __main__ and lists the functions it defined
+// or redefined
+const defineHelper = `
+import types
+
+def _gopython_define(code):
+    import __main__
+    namespace = __main__.__dict__
+    before = dict(namespace)
+    exec(compile(code, '<string>', 'exec'), namespace)
+    return [name for name, value in namespace.items()
+            if isinstance(value, types.FunctionType) and before.get(name) is not value]
+`
+
+// DefineAndBind executes code in __main__ and returns a Go closure for every
+// function it defines, keyed by function name. Each closure calls its
+// function directly, converting arguments and results like CallFunction, and
+// keeps calling that definition even if code run otherwise redefines the
+// name. Defining the name again with DefineAndBind releases the previous
+// definition instead, and closures returned for it earlier report that they
+// are no longer valid, so calling DefineAndBind repeatedly holds one function
+// per name. The closures stop working when the interpreter is finalized.
+//
+// Example:
+//
+//	funcs, err := py.DefineAndBind(`
+//	def add(a, b):
+//	    return a + b
+//
+//	def greet(name):
+//	    return f"Hello, {name}!"
+//	`)
+//	sum, err := funcs["add"](2, 3)
+func (py *PureGoPython) DefineAndBind(code string) (map[string]func(...interface{}) (interface{}, error), error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+
+	result, err := py.withGILReturn(func() (interface{}, error) {
+		namesObj, err := py.callHelper(defineHelper, "_gopython_define", code)
+		if err != nil {
+			return nil, err
+		}
+		defer py.safeDecRef(namesObj)
+
+		names, err := py.internalToGo(PyObject(namesObj))
+		if err != nil {
+			return nil, err
+		}
+
+		funcs := make(map[string]func(...interface{}) (interface{}, error))
+		for _, name := range names.([]interface{}) {
+			function := name.(string)
+			functionObj, err := py.lookupFunction("__main__", function)
+			if err != nil {
+				return nil, err
+			}
+			if previous, ok := py.boundFuncs[function]; ok {
+				previous.drop()
+			}
+			if py.boundFuncs == nil {
+				py.boundFuncs = make(map[string]*PyHandle)
+			}
+			h := py.newHandle(functionObj)
+			py.boundFuncs[function] = h
+			funcs[function] = (&PreparedCall{module: "__main__", function: function, handle: h}).Call
+		}
+		return funcs, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(map[string]func(...interface{}) (interface{}, error)), nil
+}
+
+// callHelper executes source, which defines a Python helper function, in a
+// private namespace and calls the function named function. It runs without
+// GIL management and returns a new reference to the unconverted result.
+func (py *PureGoPython) callHelper(source, function string, args ...interface{}) (uintptr, error) {
+	globals := py.pyDictNew()
+	if globals == 0 {
+		return 0, errors.New("failed to create helper namespace")
+	}
+	defer py.safeDecRef(globals)
+
+	builtins, err := py.importModule("builtins")
+	if err != nil {
+		return 0, err
+	}
+	defer py.safeDecRef(builtins)
+	if py.pyDictSetItemString(globals, stringToCString("__builtins__"), builtins) != 0 {
+		return 0, fmt.Errorf("failed to seed builtins: %w", py.getPythonError())
+	}
+
+	resultObj := py.pyRunStringFlags(stringToCString(source), pyFileInput, globals, globals, 0)
+	if resultObj == 0 {
+		return 0, fmt.Errorf("failed to define helper '%s': %w", function, py.getPythonError())
+	}
+	py.safeDecRef(resultObj)
+
+	// PyDict_GetItemString returns a borrowed reference
+	functionObj := py.pyDictGetItemString(globals, stringToCString(function))
+	if functionObj == 0 {
+		return 0, fmt.Errorf("helper '%s' is not defined", function)
+	}
+	return py.callObject(functionObj, args...)
+}
+
+// namespaceToMap converts the public, convertible entries of a namespace
+// dict to Go values without GIL management
+func (py *PureGoPython) namespaceToMap(dict uintptr) map[string]interface{} {
+	result := make(map[string]interface{})
+	keys := py.pyDictKeys(dict)
+	if keys == 0 {
+		py.pyErrClear()
+		return result
+	}
+	defer py.safeDecRef(keys)
+
+	for i := 0; i < py.pyListSize(keys); i++ {
+		cKey := py.pyUnicodeAsUTF8(py.pyListGetItem(keys, i))
+		if cKey == nil {
+			py.pyErrClear()
+			continue
+		}
+		key := cStringToGoString(cKey)
+		if strings.HasPrefix(key, "__") {
+			continue
+		}
+
+		// PyDict_GetItemString returns a borrowed reference, or NULL if the
+		// entry was removed while converting an earlier value
+		valueObj := py.pyDictGetItemString(dict, cKey)
+		if valueObj == 0 {
+			continue
+		}
+		py.pyIncRef(valueObj)
+		value, err := py.pythonToGo(PyObject(valueObj))
+		py.safeDecRef(valueObj)
+		if err != nil && !isPartial(err) {
+			continue // Functions, classes, modules and other unconvertible values
+		}
+		result[key] = value
+	}
+	return result
+}
+
+// runFileHelper executes a script in __main__ the way the python command
+// does: __file__ is set, the script directory is importable and tracebacks
+// show the script path. sys.exit() ends the script instead of the process.
+// Like runpy.run_path, it restores sys.path, sys.argv and the __file__ and
+// __cached__ globals afterwards, even if the script fails.
+const runFileHelper = `
+def _gopython_run_file(path):
+    import __main__, os, sys
+    with open(path, 'rb') as f:
+        code = compile(f.read(), path, 'exec')
+    namespace = __main__.__dict__
+    missing = object()
+    saved_globals = {name: namespace.get(name, missing) for name in ('__file__', '__cached__')}
+    saved_argv = getattr(sys, 'argv', missing)
+    namespace['__file__'] = path
+    namespace['__cached__'] = None
+    script_dir = os.path.dirname(path)
+    sys.path.insert(0, script_dir)
+    if not getattr(sys, 'argv', None) or sys.argv == ['']:
+        sys.argv = [path]
+    try:
+        exec(code, namespace)
+    except SystemExit as e:
+        if e.code not in (None, 0):
+            raise RuntimeError(f"script exited with status {e.code}") from None
+    finally:
+        try:
+            sys.path.remove(script_dir)
+        except ValueError:
+            pass
+        if saved_argv is missing:
+            sys.__dict__.pop('argv', None)
+        else:
+            sys.argv = saved_argv
+        for name, value in saved_globals.items():
+            if value is missing:
+                namespace.pop(name, None)
+            else:
+                namespace[name] = value
+`
+
+// RunFile executes a Python script in the __main__ module with the same
+// semantics as running it with the python command: __name__ is "__main__",
+// __file__ holds the script's absolute path and the script's directory is
+// added to sys.path so sibling modules and data files can be found. Once the
+// script ends, sys.path, sys.argv and __file__ are restored, while the
+// functions and variables it defined stay in __main__.
+func (py *PureGoPython) RunFile(filename string) error {
+	if !py.IsInitialized() {
+		return ErrNotInitialized
+	}
+
+	path, err := resolveScript(filename)
+	if err != nil {
+		return err
+	}
+
+	return py.withGIL(func() error {
+		resultObj, err := py.callHelper(runFileHelper, "_gopython_run_file", path)
+		if err != nil {
+			return fmt.Errorf("failed to run file %s: %w", filename, err)
+		}
+		py.safeDecRef(resultObj)
+		return nil
+	})
+}
+
+// resolveScript checks that the script filename exists and returns its
+// absolute path
+func resolveScript(filename string) (string, error) {
+	if _, err := os.Stat(filename); os.IsNotExist(err) {
+		return "", fmt.Errorf("file does not exist: %s", filename)
+	}
+
+	path, err := filepath.Abs(filename)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path %s: %v", filename, err)
+	}
+	return path, nil
+}
+
+// chdirHelper changes the working directory and returns the previous one
+const chdirHelper = `
+def _gopython_chdir(path):
+    import os
+    previous = os.getcwd()
+    os.chdir(path)
+    return previous
+`
+
+// WithWorkingDir runs fn with Python's working directory set to dir, so
+// scripts that open relative paths behave the same wherever the Go program
+// was started. The previous directory is restored when fn returns, fails or
+// panics. The working directory belongs to the whole process, so Go code and
+// other goroutines see the change while fn runs.
+//
+// Example:
+//
+//	err := py.WithWorkingDir("testdata", func() error {
+//	    return py.RunString("data = open('input.txt').read()")
+//	})
+func (py *PureGoPython) WithWorkingDir(dir string, fn func() error) (err error) {
+	if !py.IsInitialized() {
+		return ErrNotInitialized
+	}
+
+	chdir := func(path string) (string, error) {
+		result, err := py.withGILReturn(func() (interface{}, error) {
+			resultObj, err := py.callHelper(chdirHelper, "_gopython_chdir", path)
+			if err != nil {
+				return nil, err
+			}
+			defer py.safeDecRef(resultObj)
+			return py.objectToString(resultObj)
+		})
+		if err != nil {
+			return "", err
+		}
+		return result.(string), nil
+	}
+
+	previous, err := chdir(dir)
+	if err != nil {
+		return fmt.Errorf("failed to change working directory to %s: %v", dir, err)
+	}
+	defer func() {
+		if _, restoreErr := chdir(previous); restoreErr != nil && err == nil {
+			err = fmt.Errorf("failed to restore working directory %s: %v", previous, restoreErr)
+		}
+	}()
+
+	return fn()
+}
+
+// warmUpHelper touches every public name of a module, those in __all__ or
+// else those dir() lists, so attributes and submodules that are loaded lazily
+// on first access are initialized, then calls the warm-up function if given
+const warmUpHelper = `
+def _gopython_warm_up(module, function):
+    names = getattr(module, '__all__', None)
+    if names is None:
+        names = [name for name in dir(module) if not name.startswith('_')]
+    for name in names:
+        try:
+            getattr(module, name)
+        except Exception:
+            pass
+    if function:
+        getattr(module, function)()
+`
+
+// WarmUp imports the given modules ahead of time and touches the public names
+// of each, those in __all__ or else those dir() lists, triggering lazy
+// initialization, so the first real call is not slowed down by imports.
+// Modules that defer work to their first call can be given as
+// "module:function", which also calls function without arguments, e.g.
+// "uuid:uuid4". Every module is attempted; the returned error lists the ones
+// that failed. Use WarmUpTimings to see how long each module took.
+func (py *PureGoPython) WarmUp(modules []string) error {
+	_, err := py.WarmUpTimings(modules)
+	return err
+}
+
+// WarmUpTimings is WarmUp returning how long each successfully warmed module
+// took to import and initialize, keyed like modules. The time spent waiting
+// for the interpreter is not included.
+func (py *PureGoPython) WarmUpTimings(modules []string) (map[string]time.Duration, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+
+	timings := make(map[string]time.Duration)
+	var failures []string
+	for _, entry := range modules {
+		module, function, _ := strings.Cut(entry, ":")
+		var elapsed time.Duration
+		err := py.withGIL(func() error {
+			start := time.Now()
+			moduleObj, err := py.importModule(module)
+			if err != nil {
+				return err
+			}
+			defer py.safeDecRef(moduleObj)
+
+			handle := py.newHandle(moduleObj)
+			resultObj, err := py.callHelper(warmUpHelper, "_gopython_warm_up", handle, function)
+			if err != nil {
+				return err
+			}
+			py.safeDecRef(resultObj)
+			elapsed = time.Since(start)
+			return nil
+		})
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", entry, err))
+			continue
+		}
+		timings[entry] = elapsed
+	}
+
+	if len(failures) > 0 {
+		return timings, fmt.Errorf("failed to warm up modules: %s", strings.Join(failures, "; "))
+	}
+	return timings, nil
+}
+
+// CallFunction calls a Python function with the given arguments. Each
+// argument becomes one positional argument, so a slice passed as-is arrives
+// as a single list, while a []interface{} spread with ... arrives as separate
+// arguments, filling *args:
+//
+//	items := []interface{}{1, 2, 3}
+//	py.CallFunction("m", "f", items)    // f([1, 2, 3])
+//	py.CallFunction("m", "f", items...) // f(1, 2, 3)
+//
+// CallFunctionArgsSlice makes the spreading form explicit.
+func (py *PureGoPython) CallFunction(module, function string, args ...interface{}) (interface{}, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+
+	return py.withGILReturn(func() (interface{}, error) {
+		return py.callFunctionUnsafe(module, function, args...)
+	})
+}
+
+// CallFunctionArgsSlice calls a Python function with the elements of args as
+// its positional arguments. It is equivalent to CallFunction(module, function,
+// args...) and avoids accidentally passing the whole slice as one list when
+// the ... is forgotten. A nil or empty slice calls the function with no
+// arguments.
+func (py *PureGoPython) CallFunctionArgsSlice(module, function string, args []interface{}) (interface{}, error) {
+	return py.CallFunction(module, function, args...)
+}
+
+// ResultAsString calls a Python function like CallFunction and returns its
+// result formatted as a string for display or logging. Strings are returned
+// unchanged and floats are formatted with strconv's shortest representation,
+// or with Python's repr when SetFloatRepr is enabled. Other values are
+// formatted with fmt.Sprint after conversion.
+func (py *PureGoPython) ResultAsString(module, function string, args ...interface{}) (string, error) {
+	if !py.IsInitialized() {
+		return "", ErrNotInitialized
+	}
+
+	result, err := py.withGILReturn(func() (interface{}, error) {
+		resultObj, err := py.callFunctionObject(module, function, args...)
+		if err != nil {
+			return nil, err
+		}
+		defer py.safeDecRef(resultObj)
+
+		if py.floatRepr && py.isFloat(PyObject(resultObj)) {
+			return py.objectRepr(resultObj)
+		}
+		value, err := py.pythonToGo(PyObject(resultObj))
+		if err != nil && !isPartial(err) {
+			return nil, err
+		}
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case float64:
+			return strconv.FormatFloat(v, 'g', -1, 64), nil
+		}
+		// Placeholders of skipped elements are only needed for formatting
+		defer CloseAll(value)
+		return fmt.Sprint(value), err
+	})
+	if result == nil {
+		return "", err
+	}
+	return result.(string), err
+}
+
+// CallFunctionKwargs calls a Python function with positional and keyword
+// arguments. Keyword argument values are converted like positional ones.
+func (py *PureGoPython) CallFunctionKwargs(module, function string, args []interface{}, kwargs map[string]interface{}) (interface{}, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+
+	return py.withGILReturn(func() (interface{}, error) {
+		functionObj, err := py.lookupFunction(module, function)
+		if err != nil {
+			return nil, err
+		}
+		defer py.safeDecRef(functionObj)
+
+		resultObj, err := py.callObjectKwargs(functionObj, args, kwargs)
+		if err != nil {
+			return nil, err
+		}
+		defer py.safeDecRef(resultObj)
+
+		return py.pythonToGo(PyObject(resultObj))
+	})
+}
+
+// CallFunctionOrderedKwargs calls a Python function like CallFunctionKwargs,
+// passing the keyword arguments in the given order. Functions taking
+// **kwargs see them in that order, e.g. when logging or building output
+// from them.
+//
+// Example:
+//
+//	result, err := py.CallFunctionOrderedKwargs("report", "render", nil, gopython.OrderedKwargs{
+//	    {Key: "title", Value: "Sales"},
+//	    {Key: "year", Value: 2024},
+//	})
+func (py *PureGoPython) CallFunctionOrderedKwargs(module, function string, args []interface{}, kwargs OrderedKwargs) (interface{}, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+
+	seen := make(map[string]bool, len(kwargs))
+	for _, kwarg := range kwargs {
+		if seen[kwarg.Key] {
+			return nil, fmt.Errorf("duplicate keyword argument '%s'", kwarg.Key)
+		}
+		seen[kwarg.Key] = true
+	}
+
+	return py.withGILReturn(func() (interface{}, error) {
+		functionObj, err := py.lookupFunction(module, function)
+		if err != nil {
+			return nil, err
+		}
+		defer py.safeDecRef(functionObj)
+
+		var kwargsDict PyObject
+		if len(kwargs) > 0 {
+			kwargsDict, err = py.orderedToPythonDict(OrderedDictValue(kwargs), 0)
+			if err != nil {
+				return nil, fmt.Errorf("failed to build keyword arguments: %v", err)
+			}
+			defer py.safeDecRef(uintptr(kwargsDict))
+		}
+
+		resultObj, err := py.callObjectDict(functionObj, args, kwargsDict, orderedToMap(OrderedDictValue(kwargs)))
+		if err != nil {
+			return nil, err
+		}
+		defer py.safeDecRef(resultObj)
+
+		return py.pythonToGo(PyObject(resultObj))
+	})
+}
+
+// CallFunctionRaw calls a Python function like CallFunction but returns the
+// result as a handle instead of converting it to a Go value. The caller owns
+// the handle and must Close it when done. Handles can be passed back as
+// arguments to later calls.
+func (py *PureGoPython) CallFunctionRaw(module, function string, args ...interface{}) (*PyHandle, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+
+	result, err := py.withGILReturn(func() (interface{}, error) {
+		resultObj, err := py.callFunctionObject(module, function, args...)
+		if err != nil {
+			return nil, err
+		}
+		return py.newHandle(resultObj), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*PyHandle), nil
+}
+
+// coroutineHelper drives an awaitable to completion on an event loop that is
+// kept on sys between calls, so objects bound to a loop (client sessions,
+// connection pools) remain usable. If the cached loop is running on another
+// thread, a temporary loop is used instead.
+const coroutineHelper = `
+import asyncio
+import inspect
+import sys
+
+def _gopython_run_coroutine(awaitable):
+    if not inspect.isawaitable(awaitable):
+        raise TypeError(f"expected a coroutine, got {type(awaitable).__name__}")
+    loop = getattr(sys, '_gopython_event_loop', None)
+    if loop is None or loop.is_closed():
+        loop = asyncio.new_event_loop()
+        sys._gopython_event_loop = loop
+    if loop.is_running():
+        return asyncio.run(awaitable)
+    return loop.run_until_complete(awaitable)
+`
+
+// RunCoroutine calls an async Python function and drives the returned
+// coroutine to completion, converting the awaited result to a Go value.
+// Coroutines run on an event loop that persists across calls until Finalize.
+//
+// Example:
+//
+//	// async def fetch(url): ...
+//	body, err := py.RunCoroutine("client", "fetch", "https://example.com")
+func (py *PureGoPython) RunCoroutine(module, function string, args ...interface{}) (interface{}, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+
+	return py.withGILReturn(func() (interface{}, error) {
+		coroutine, err := py.callFunctionObject(module, function, args...)
+		if err != nil {
+			return nil, err
+		}
+		handle := py.newHandle(coroutine)
+		defer py.safeDecRef(handle.obj)
+
+		resultObj, err := py.callHelper(coroutineHelper, "_gopython_run_coroutine", handle)
+		if err != nil {
+			return nil, fmt.Errorf("coroutine '%s' failed: %w", function, err)
+		}
+		defer py.safeDecRef(resultObj)
+
+		return py.pythonToGo(PyObject(resultObj))
+	})
+}
+
+// faultHelper enables faulthandler on a file descriptor for the duration of
+// a call and restores the previous state afterwards
+const faultHelper = `
+import faulthandler
+import sys
+
+def _gopython_fault_enable(fd):
+    was_enabled = faulthandler.is_enabled()
+    faulthandler.enable(file=fd, all_threads=True)
+    return was_enabled
+
+def _gopython_fault_restore(was_enabled):
+    if was_enabled and sys.__stderr__ is not None:
+        faulthandler.enable(file=sys.__stderr__, all_threads=True)
+    else:
+        faulthandler.disable()
+`
+
+// SafeCall calls a Python function like CallFunction, with faulthandler
+// enabled so that a fatal signal raised during the call (for example a
+// segfault in a buggy C extension) dumps the Python traceback of every thread
+// to w before the process dies. Ordinary Python exceptions are returned as
+// errors as usual; fatal signals cannot be recovered from.
+//
+// Pass an *os.File (such as os.Stderr or a log file) for a reliable dump: it
+// is written directly to the file descriptor. Other writers receive the dump
+// through a pipe, which may not be drained before the process exits.
+//
+// If faulthandler was already enabled it is re-enabled on sys.__stderr__
+// afterwards.
+func (py *PureGoPython) SafeCall(w io.Writer, module, function string, args ...interface{}) (interface{}, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+	if w == nil {
+		return nil, errors.New("writer cannot be nil")
+	}
+
+	var fd uintptr
+	if f, ok := w.(*os.File); ok {
+		fd = f.Fd()
+	} else {
+		reader, writer, err := os.Pipe()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create fault dump pipe: %v", err)
+		}
+		copied := make(chan struct{})
+		go func() {
+			defer close(copied)
+			io.Copy(w, reader)
+			reader.Close()
+		}()
+		defer func() {
+			writer.Close()
+			<-copied
+		}()
+		fd = writer.Fd()
+	}
+
+	return py.withGILReturn(func() (interface{}, error) {
+		enabledObj, err := py.callHelper(faultHelper, "_gopython_fault_enable", int64(fd))
+		if err != nil {
+			return nil, fmt.Errorf("failed to enable faulthandler: %w", err)
+		}
+		wasEnabled, err := py.internalToGo(PyObject(enabledObj))
+		py.safeDecRef(enabledObj)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if restored, err := py.callHelper(faultHelper, "_gopython_fault_restore", wasEnabled); err == nil {
+				py.safeDecRef(restored)
+			}
+		}()
+
+		return py.callFunctionUnsafe(module, function, args...)
+	})
+}
+
+// callFunctionUnsafe performs the actual function call without GIL management
+func (py *PureGoPython) callFunctionUnsafe(module, function string, args ...interface{}) (interface{}, error) {
+	resultObj, err := py.callFunctionObject(module, function, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer py.safeDecRef(resultObj)
+
+	// Convert result to Go
+	return py.pythonToGo(PyObject(resultObj))
+}
+
+// callFunctionObject calls a Python function without GIL management and
+// returns a new reference to the unconverted result
+func (py *PureGoPython) callFunctionObject(module, function string, args ...interface{}) (uintptr, error) {
+	functionObj, err := py.lookupFunction(module, function)
+	if err != nil {
+		return 0, err
+	}
+	defer py.safeDecRef(functionObj)
+
+	return py.callObject(functionObj, args...)
+}
+
+// lookupFunction imports module and returns a new reference to its attribute
+// named function without GIL management
+func (py *PureGoPython) lookupFunction(module, function string) (uintptr, error) {
+	// Import the module, or reuse it if it was imported before
+	moduleObj, err := py.cachedModule(module)
+	if err != nil {
+		return 0, err
+	}
+	defer py.safeDecRef(moduleObj)
+
+	// Get the function from the module
+	functionNameObj, err := py.goToPython(function)
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert function name: %v", err)
+	}
+	defer py.safeDecRef(uintptr(functionNameObj))
+
+	functionObj := py.pyObjectGetAttr(moduleObj, uintptr(functionNameObj))
+	if functionObj == 0 {
+		py.pyErrClear()
+		return 0, fmt.Errorf("%w: '%s' in module '%s'", ErrFunctionNotFound, function, module)
+	}
+	return functionObj, nil
+}
+
+// callObject calls a Python callable with converted arguments without GIL
+// management and returns a new reference to the unconverted result
+func (py *PureGoPython) callObject(callable uintptr, args ...interface{}) (uintptr, error) {
+	// Build argument tuple
+	argTuple, err := py.buildArgumentTuple(args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to build arguments: %w", err)
+	}
+	defer py.safeDecRef(uintptr(argTuple))
+
+	// Call the function
+	resultObj := py.pyObjectCallObject(callable, uintptr(argTuple))
+	py.syncByteArrays(argTuple, args, 0, nil)
+	if resultObj == 0 {
+		return 0, fmt.Errorf("function call failed: %w", py.getPythonError())
+	}
+	return resultObj, nil
+}
+
+// callObjectKwargs calls a Python callable with converted positional and
+// keyword arguments without GIL management and returns a new reference to the
+// unconverted result
+func (py *PureGoPython) callObjectKwargs(callable uintptr, args []interface{}, kwargs map[string]interface{}) (uintptr, error) {
+	var kwargsDict PyObject
+	if len(kwargs) > 0 {
+		var err error
+		kwargsDict, err = py.mapToPythonDict(kwargs, 0)
+		if err != nil {
+			return 0, fmt.Errorf("failed to build keyword arguments: %v", err)
+		}
+		defer py.safeDecRef(uintptr(kwargsDict))
+	}
+
+	return py.callObjectDict(callable, args, kwargsDict, kwargs)
+}
+
+// callObjectDict calls a Python callable with converted positional arguments
+// and an already built kwargs dict (0 for none), without GIL management.
+// kwargs holds the Go values the dict was built from, for ByteArray syncing.
+func (py *PureGoPython) callObjectDict(callable uintptr, args []interface{}, kwargsDict PyObject, kwargs map[string]interface{}) (uintptr, error) {
+	argTuple, err := py.buildArgumentTuple(args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to build arguments: %w", err)
+	}
+	defer py.safeDecRef(uintptr(argTuple))
+
+	resultObj := py.pyObjectCall(callable, uintptr(argTuple), uintptr(kwargsDict))
+	py.syncByteArrays(argTuple, args, kwargsDict, kwargs)
+	if resultObj == 0 {
+		return 0, fmt.Errorf("function call failed: %w", py.getPythonError())
+	}
+	return resultObj, nil
+}
+
+// importModule imports a Python module without GIL management and returns a
+// new reference to it
+func (py *PureGoPython) importModule(module string) (uintptr, error) {
+	moduleNameObj, err := py.goToPython(module)
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert module name: %v", err)
+	}
+	defer py.safeDecRef(uintptr(moduleNameObj))
+
+	moduleObj := py.pyImportImport(uintptr(moduleNameObj))
+	if moduleObj == 0 {
+		err := py.getPythonError()
+		if isMissingModule(err, module) {
+			return 0, fmt.Errorf("%w: '%s': %w", ErrModuleNotFound, module, err)
+		}
+		return 0, fmt.Errorf("failed to import module '%s': %w", module, err)
+	}
+	return moduleObj, nil
+}
+
+// isMissingModule reports whether err is a ModuleNotFoundError for module
+// itself or one of its parent packages, as opposed to one raised by an
+// import statement inside the module
+func isMissingModule(err error, module string) bool {
+	var pyErr *PyError
+	if !errors.As(err, &pyErr) || pyErr.Type != "ModuleNotFoundError" {
+		return false
+	}
+	name, _ := pyErr.Attrs["name"].(string)
+	return name == module || strings.HasPrefix(module, name+".")
+}
+
+// CallPyFunction calls a Python function with type-safe generics for request and response types.
+// The request is passed as the only argument, so a nil request is passed as None.
+// Results that are not already a TResponse are decoded into it with DecodeResult.
+func CallPyFunction[TRequest, TResponse any](py *PureGoPython, module, function string, request TRequest) (TResponse, error) {
+	var zero TResponse
+
+	if !py.IsInitialized() {
+		return zero, ErrNotInitialized
+	}
+
+	// Call the underlying CallFunction with the request
+	result, err := py.CallFunction(module, function, request)
+	if err != nil {
+		CloseAll(result) // Placeholders of elements skipped by SetSkipUnconvertible
+		return zero, err
+	}
+
+	response, err := decodeAs[TResponse](result)
+	if err != nil {
+		return zero, fmt.Errorf("failed to convert result to %T: %v", zero, err)
+	}
+	return response, nil
+}
+
+// CallPyFunction2 calls a Python function that returns a 2-tuple, such as
+// (result, metadata), and decodes its items positionally into R1 and R2 like
+// CallPyFunction does for a single result.
+//
+// Example:
+//
+//	status, body, err := gopython.CallPyFunction2[string, int, string](py, "client", "fetch", url)
+func CallPyFunction2[TRequest, R1, R2 any](py *PureGoPython, module, function string, request TRequest) (R1, R2, error) {
+	var zero1 R1
+	var zero2 R2
+
+	items, err := callUnpacked(py, module, function, request, 2)
+	if err != nil {
+		return zero1, zero2, err
+	}
+
+	first, err := decodeAs[R1](items[0])
+	if err != nil {
+		return zero1, zero2, fmt.Errorf("failed to convert result item 0 to %T: %v", zero1, err)
+	}
+	second, err := decodeAs[R2](items[1])
+	if err != nil {
+		return zero1, zero2, fmt.Errorf("failed to convert result item 1 to %T: %v", zero2, err)
+	}
+	return first, second, nil
+}
+
+// CallPyFunction3 is CallPyFunction2 for functions returning a 3-tuple
+func CallPyFunction3[TRequest, R1, R2, R3 any](py *PureGoPython, module, function string, request TRequest) (R1, R2, R3, error) {
+	var zero1 R1
+	var zero2 R2
+	var zero3 R3
+
+	items, err := callUnpacked(py, module, function, request, 3)
+	if err != nil {
+		return zero1, zero2, zero3, err
+	}
+
+	first, err := decodeAs[R1](items[0])
+	if err != nil {
+		return zero1, zero2, zero3, fmt.Errorf("failed to convert result item 0 to %T: %v", zero1, err)
+	}
+	second, err := decodeAs[R2](items[1])
+	if err != nil {
+		return zero1, zero2, zero3, fmt.Errorf("failed to convert result item 1 to %T: %v", zero2, err)
+	}
+	third, err := decodeAs[R3](items[2])
+	if err != nil {
+		return zero1, zero2, zero3, fmt.Errorf("failed to convert result item 2 to %T: %v", zero3, err)
+	}
+	return first, second, third, nil
+}
+
+// callUnpacked calls a Python function and checks that it returned a tuple
+// (or list) of exactly n items
+func callUnpacked[TRequest any](py *PureGoPython, module, function string, request TRequest, n int) ([]interface{}, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+
+	result, err := py.CallFunction(module, function, request)
+	if err != nil {
+		CloseAll(result) // Placeholders of elements skipped by SetSkipUnconvertible
+		return nil, err
+	}
+
+	items, ok := result.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected '%s' to return a tuple of %d items, got %T", function, n, result)
+	}
+	if len(items) != n {
+		return nil, fmt.Errorf("expected '%s' to return a tuple of %d items, got %d", function, n, len(items))
+	}
+	return items, nil
+}
+
+// decodeAs returns value as a T, using it directly when it already has that
+// type and otherwise decoding it (a dict into a struct, a list into a typed
+// slice)
+func decodeAs[T any](value interface{}) (T, error) {
+	if typed, ok := value.(T); ok {
+		return typed, nil
+	}
+	var decoded T
+	err := DecodeResult(value, &decoded)
+	return decoded, err
+}
+
+// TypedFunc resolves a Python function once and returns a closure that calls
+// it, converting the result to TResp. Results that are not already a TResp
+// are decoded into it where possible (an int into a float64, a dict into a
+// struct, a list into a typed slice). The function is resolved with Prepare,
+// so the closure is cheap to call repeatedly.
+//
+// Example:
+//
+//	sqrt, err := gopython.TypedFunc[float64](py, "math", "sqrt")
+//	for i := 1; i <= 10; i++ {
+//	    root, err := sqrt(i)
+//	    ...
+//	}
+func TypedFunc[TResp any](py *PureGoPython, module, function string) (func(args ...interface{}) (TResp, error), error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+
+	prepared, err := py.Prepare(module, function)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(args ...interface{}) (TResp, error) {
+		var response TResp
+		result, err := prepared.Call(args...)
+		if err != nil {
+			return response, err
+		}
+
+		if typed, ok := result.(TResp); ok {
+			return typed, nil
+		}
+		if err := decodeValue(result, reflect.ValueOf(&response).Elem()); err != nil {
+			return response, fmt.Errorf("failed to convert result of '%s' to %T: %v", function, response, err)
+		}
+		return response, nil
+	}, nil
+}
+
+// PyError is a Python exception converted to Go. Errors returned for a
+// failed call wrap a *PyError, so it can be extracted with errors.As:
+//
+//	var pyErr *gopython.PyError
+//	if errors.As(err, &pyErr) && pyErr.Type == "FileNotFoundError" {
+//	    log.Printf("missing %v (errno %v)", pyErr.Attrs["filename"], pyErr.Attrs["errno"])
+//	}
+type PyError struct {
+	Type    string                 // Exception class name, e.g. "KeyError"
+	Message string                 // str() of the exception
+	Args    []interface{}          // exc.args converted to Go, nil entries for unconvertible values
+	Attrs   map[string]interface{} // Structured attributes present on the exception, see exceptionAttrs
+}
+
+// Error implements the error interface
+func (e *PyError) Error() string {
+	return fmt.Sprintf("Python error: %s", e.Message)
+}
+
+// exceptionAttrs are the attributes copied into PyError.Attrs when an
+// exception has them: OSError's errno/strerror/filename, ImportError's
+// name/path, SystemExit's code, StopIteration's value and UnicodeError's
+// encoding/reason/start/end. KeyError's missing key is stored as "key".
+var exceptionAttrs = []string{
+	"errno", "strerror", "filename", "filename2", "winerror",
+	"name", "path", "code", "value",
+	"encoding", "reason", "start", "end",
+}
+
+// getPythonError extracts Python error information into a *PyError and
+// clears the error indicator
+func (py *PureGoPython) getPythonError() error {
+	if py.pyErrOccurred() == 0 {
+		return errors.New("unknown Python error")
+	}
+
+	var ptype, pvalue, ptraceback uintptr
+	py.pyErrFetch(&ptype, &pvalue, &ptraceback)
+	py.pyErrNormalizeException(&ptype, &pvalue, &ptraceback)
+	defer py.safeDecRef(ptype)
+	defer py.safeDecRef(pvalue)
+	defer py.safeDecRef(ptraceback)
+
+	// Clear the error state
+	py.pyErrClear()
+
+	if pvalue == 0 {
+		return errors.New("Python error occurred but no error message available")
+	}
+
+	// Convert error to string
+	errorStr := py.pyObjectStr(pvalue)
+	if errorStr == 0 {
+		py.pyErrClear()
+		return errors.New("Python error occurred but failed to get error string")
+	}
+	defer py.safeDecRef(errorStr)
+
+	pyErr := &PyError{
+		Type:    py.getTypeName(PyObject(pvalue)),
+		Message: "Python error",
+	}
+	if cStr := py.pyUnicodeAsUTF8(errorStr); cStr != nil {
+		pyErr.Message = cStringToGoString(cStr)
+	}
+	py.fillErrorDetails(pyErr, pvalue)
+	return pyErr
+}
+
+// fillErrorDetails reads the args and structured attributes of an exception
+// instance into pyErr. Values that cannot be converted are skipped.
+func (py *PureGoPython) fillErrorDetails(pyErr *PyError, exc uintptr) {
+	if argsObj := py.getAttrString(exc, "args"); argsObj != 0 {
+		if py.isTuple(PyObject(argsObj)) {
+			pyErr.Args = make([]interface{}, py.pyTupleSize(argsObj))
+			for i := range pyErr.Args {
+				// PyTuple_GetItem returns a borrowed reference
+				value, err := py.internalToGo(PyObject(py.pyTupleGetItem(argsObj, i)))
+				if err != nil {
+					py.pyErrClear()
+					continue
+				}
+				pyErr.Args[i] = value
+			}
+		}
+		py.safeDecRef(argsObj)
+	}
+
+	for _, name := range exceptionAttrs {
+		if py.pyObjectHasAttrString(exc, stringToCString(name)) == 0 {
+			continue
+		}
+		if value := py.attrValue(exc, name); value != nil {
+			if pyErr.Attrs == nil {
+				pyErr.Attrs = make(map[string]interface{})
+			}
+			pyErr.Attrs[name] = value
+		}
+	}
+
+	if pyErr.Type == "KeyError" && len(pyErr.Args) == 1 {
+		if pyErr.Attrs == nil {
+			pyErr.Attrs = make(map[string]interface{})
+		}
+		pyErr.Attrs["key"] = pyErr.Args[0]
+	}
+}
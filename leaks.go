@@ -0,0 +1,164 @@
+package gopython
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrLeakDetected is returned by CheckLeaks when the interpreter keeps
+// growing while a workload is repeated
+var ErrLeakDetected = errors.New("leak detected")
+
+// leakHelper samples the interpreter's allocations after a full collection
+const leakHelper = `
+import gc
+import sys
+
+def _gopython_leak_sample():
+    gc.collect()
+    total = getattr(sys, "gettotalrefcount", None)
+    return [sys.getallocatedblocks(), len(gc.get_objects()), total() if total else -1]
+`
+
+// LeakSample is a snapshot of the interpreter's allocations, taken after a
+// full garbage collection
+type LeakSample struct {
+	Blocks   int64 // Memory blocks allocated by Python, from sys.getallocatedblocks
+	Objects  int64 // Objects tracked by the garbage collector
+	RefTotal int64 // Total reference count on debug builds of Python, -1 otherwise
+}
+
+// LeakReport holds the samples CheckLeaks took after warming up, halfway
+// through the rounds and at the end
+type LeakReport struct {
+	Rounds int
+	Start  LeakSample
+	Middle LeakSample
+	End    LeakSample
+}
+
+// Growth returns how much each measure grew between the first and the last
+// sample
+func (r *LeakReport) Growth() LeakSample {
+	growth := LeakSample{
+		Blocks:   r.End.Blocks - r.Start.Blocks,
+		Objects:  r.End.Objects - r.Start.Objects,
+		RefTotal: -1,
+	}
+	if r.Start.RefTotal >= 0 {
+		growth.RefTotal = r.End.RefTotal - r.Start.RefTotal
+	}
+	return growth
+}
+
+// leaking reports whether a measure grew by at least one unit per round in
+// both halves of the run. Caches and free lists settle after a while, so
+// bounded growth stops by the second half, while a leak keeps going.
+func leaking(start, middle, end int64, firstHalf, secondHalf int) bool {
+	if start < 0 {
+		return false
+	}
+	return middle-start >= int64(firstHalf) && end-middle >= int64(secondHalf)
+}
+
+// CheckLeaks runs workload once per round, after a few warm-up runs that let
+// imports and caches settle, and samples the interpreter's allocated blocks,
+// garbage-collected objects and, on debug builds, total reference count. It
+// returns an error wrapping ErrLeakDetected, along with the report, if any of
+// them kept growing by at least one per round through both halves of the run.
+// Use it in regression tests for long-running services:
+//
+//	report, err := py.CheckLeaks(2000, func() error {
+//	    _, err := py.CallFunction("model", "score", record)
+//	    return err
+//	})
+//	if errors.Is(err, gopython.ErrLeakDetected) {
+//	    log.Fatalf("leaking %d blocks per 2000 calls", report.Growth().Blocks)
+//	}
+//
+// workload runs without the interpreter lock held, so it can call any method
+// of the runtime. rounds must be at least 10. Workloads that fill their own
+// caches may be reported as leaking until the caches are full.
+func (py *PureGoPython) CheckLeaks(rounds int, workload func() error) (*LeakReport, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+	if rounds < 10 {
+		return nil, fmt.Errorf("rounds must be at least 10, got %d", rounds)
+	}
+	if workload == nil {
+		return nil, errors.New("workload cannot be nil")
+	}
+
+	run := func(count int) error {
+		for i := 0; i < count; i++ {
+			if err := workload(); err != nil {
+				return fmt.Errorf("workload failed: %w", err)
+			}
+		}
+		return nil
+	}
+
+	if err := run(max(rounds/4, 1)); err != nil {
+		return nil, err
+	}
+	report := &LeakReport{Rounds: rounds}
+	firstHalf, secondHalf := rounds/2, rounds-rounds/2
+	var err error
+	if report.Start, err = py.leakSample(); err != nil {
+		return nil, err
+	}
+	if err := run(firstHalf); err != nil {
+		return nil, err
+	}
+	if report.Middle, err = py.leakSample(); err != nil {
+		return nil, err
+	}
+	if err := run(secondHalf); err != nil {
+		return nil, err
+	}
+	if report.End, err = py.leakSample(); err != nil {
+		return nil, err
+	}
+
+	var leaks []string
+	if leaking(report.Start.Blocks, report.Middle.Blocks, report.End.Blocks, firstHalf, secondHalf) {
+		leaks = append(leaks, fmt.Sprintf("%d blocks", report.End.Blocks-report.Start.Blocks))
+	}
+	if leaking(report.Start.Objects, report.Middle.Objects, report.End.Objects, firstHalf, secondHalf) {
+		leaks = append(leaks, fmt.Sprintf("%d objects", report.End.Objects-report.Start.Objects))
+	}
+	if leaking(report.Start.RefTotal, report.Middle.RefTotal, report.End.RefTotal, firstHalf, secondHalf) {
+		leaks = append(leaks, fmt.Sprintf("%d references", report.End.RefTotal-report.Start.RefTotal))
+	}
+	if len(leaks) > 0 {
+		return report, fmt.Errorf("%w: grew by %v over %d rounds", ErrLeakDetected, leaks, rounds)
+	}
+	return report, nil
+}
+
+// leakSample collects garbage and samples the interpreter's allocations
+func (py *PureGoPython) leakSample() (LeakSample, error) {
+	result, err := py.withGILReturn(func() (interface{}, error) {
+		sampleObj, err := py.callHelper(leakHelper, "_gopython_leak_sample")
+		if err != nil {
+			return nil, err
+		}
+		defer py.safeDecRef(sampleObj)
+		return py.internalToGo(PyObject(sampleObj))
+	})
+	if err != nil {
+		return LeakSample{}, fmt.Errorf("failed to sample allocations: %w", err)
+	}
+
+	values := result.([]interface{})
+	var counts [3]int64
+	for i := range counts {
+		n, err := toPlatformInt(values[i])
+		if err != nil {
+			return LeakSample{}, fmt.Errorf("failed to sample allocations: %v", err)
+		}
+		counts[i] = int64(n)
+	}
+	return LeakSample{Blocks: counts[0], Objects: counts[1], RefTotal: counts[2]}, nil
+}
@@ -1,34 +1,41 @@
 package gopython
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
 )
 
-// GetVenvSitePackagesPath returns the site-packages path for a virtual environment
+// pythonVersion is the Python version this package targets
+const pythonVersion = "3.10"
+
+// GetVenvSitePackagesPath returns the site-packages path for a virtual environment.
+// On Windows this is Lib\site-packages; elsewhere lib/pythonX.Y/site-packages.
 func GetVenvSitePackagesPath(venvPath string) (string, error) {
-	// Determine the lib directory path based on platform
-	var venvLibDir string
-	switch runtime.GOOS {
-	case "windows":
-		venvLibDir = filepath.Join(venvPath, "Lib")
-	default: // linux, darwin, etc.
-		venvLibDir = filepath.Join(venvPath, "lib")
-	}
-	
+	venvLibDir := GetVenvLibPath(venvPath)
 	if _, err := os.Stat(venvLibDir); os.IsNotExist(err) {
 		return "", fmt.Errorf("virtual environment lib directory does not exist: %s", venvLibDir)
 	}
-	
+
+	// Windows venvs have no per-version directory
+	if runtime.GOOS == "windows" {
+		sitePackages := filepath.Join(venvLibDir, "site-packages")
+		if _, err := os.Stat(sitePackages); err != nil {
+			return "", fmt.Errorf("could not find site-packages directory in virtual environment: %s", venvPath)
+		}
+		return sitePackages, nil
+	}
+
 	// Look for Python version directories
 	entries, err := os.ReadDir(venvLibDir)
 	if err != nil {
 		return "", fmt.Errorf("failed to read venv lib directory: %v", err)
 	}
-	
+
 	for _, entry := range entries {
 		if entry.IsDir() {
 			name := entry.Name()
@@ -41,17 +48,35 @@ func GetVenvSitePackagesPath(venvPath string) (string, error) {
 			}
 		}
 	}
-	
+
 	return "", fmt.Errorf("could not find site-packages directory in virtual environment: %s", venvPath)
 }
 
+// GetVenvLibPath returns the lib directory of a virtual environment
+// (Lib on Windows, lib elsewhere)
+func GetVenvLibPath(venvPath string) string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(venvPath, "Lib")
+	}
+	return filepath.Join(venvPath, "lib")
+}
+
+// GetVenvBinPath returns the directory holding a virtual environment's
+// executables (Scripts on Windows, bin elsewhere)
+func GetVenvBinPath(venvPath string) string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(venvPath, "Scripts")
+	}
+	return filepath.Join(venvPath, "bin")
+}
+
 // ValidateLibraryPath checks if a library path exists and has the expected extension
 func ValidateLibraryPath(path string) error {
 	// Check if file exists
 	if _, err := os.Stat(path); os.IsNotExist(err) {
 		return fmt.Errorf("library file does not exist: %s", path)
 	}
-	
+
 	// Check file extension based on platform
 	var expectedExt string
 	switch runtime.GOOS {
@@ -62,11 +87,132 @@ func ValidateLibraryPath(path string) error {
 	default: // linux and others
 		expectedExt = ".so"
 	}
-	
+
 	if !strings.Contains(path, expectedExt) {
-		return fmt.Errorf("library file should contain %s extension for %s, got: %s", 
+		return fmt.Errorf("library file should contain %s extension for %s, got: %s",
 			expectedExt, runtime.GOOS, path)
 	}
-	
+
 	return nil
-}
\ No newline at end of file
+}
+
+// LibPythonEnv names the environment variable that, when set, overrides the
+// search done by DiscoverLibPython with the path of the library to load
+const LibPythonEnv = "GOPYTHON_LIBPYTHON"
+
+// ErrLibPythonNotFound is wrapped by the error DiscoverLibPython returns when
+// none of the candidate locations holds the library
+var ErrLibPythonNotFound = errors.New("libpython not found")
+
+// DiscoverLibPython returns the path of the Python 3.10 shared library. If
+// GOPYTHON_LIBPYTHON is set its value is returned, or an error if it doesn't
+// point to a library; otherwise the first existing path among
+// LibPythonCandidates is returned.
+func DiscoverLibPython() (string, error) {
+	if override := os.Getenv(LibPythonEnv); override != "" {
+		if err := ValidateLibraryPath(override); err != nil {
+			return "", fmt.Errorf("%s: %v", LibPythonEnv, err)
+		}
+		return override, nil
+	}
+
+	candidates := LibPythonCandidates()
+	for _, candidate := range candidates {
+		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: could not find the Python %s shared library for %s in %d locations; pass its path explicitly or set %s",
+		ErrLibPythonNotFound, pythonVersion, runtime.GOOS, len(candidates), LibPythonEnv)
+}
+
+// LibPythonCandidates returns the paths DiscoverLibPython checks, in the
+// order it checks them.
+//
+// Linux: /usr/lib, /usr/lib/<arch>-linux-gnu, /usr/lib64, /usr/local/lib and
+// the prefix reported by python3.10-config.
+// macOS: Homebrew (Apple Silicon and Intel), python.org framework builds and
+// the prefix reported by python3.10-config.
+// Windows: install paths registered under Software\Python\PythonCore\3.10
+// and every directory on PATH.
+func LibPythonCandidates() []string {
+	var names, dirs []string
+
+	switch runtime.GOOS {
+	case "darwin":
+		names = []string{"libpython" + pythonVersion + ".dylib"}
+		dirs = []string{
+			"/opt/homebrew/lib",
+			"/opt/homebrew/opt/python@" + pythonVersion + "/Frameworks/Python.framework/Versions/" + pythonVersion + "/lib",
+			"/usr/local/lib",
+			"/usr/local/opt/python@" + pythonVersion + "/Frameworks/Python.framework/Versions/" + pythonVersion + "/lib",
+			"/Library/Frameworks/Python.framework/Versions/" + pythonVersion + "/lib",
+		}
+		dirs = append(dirs, pythonConfigLibDirs()...)
+	case "windows":
+		names = []string{"python" + strings.ReplaceAll(pythonVersion, ".", "") + ".dll"}
+		dirs = append(windowsRegistryInstallPaths(), filepath.SplitList(os.Getenv("PATH"))...)
+	default: // linux and others
+		names = []string{"libpython" + pythonVersion + ".so", "libpython" + pythonVersion + ".so.1.0"}
+		dirs = []string{
+			"/usr/lib",
+			"/usr/lib/x86_64-linux-gnu",
+			"/usr/lib/aarch64-linux-gnu",
+			"/usr/lib64",
+			"/usr/local/lib",
+		}
+		dirs = append(dirs, pythonConfigLibDirs()...)
+	}
+
+	var candidates []string
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		for _, name := range names {
+			candidates = append(candidates, filepath.Join(dir, name))
+		}
+	}
+	return candidates
+}
+
+// pythonConfigLibDirs returns library directories reported by python3.10-config
+func pythonConfigLibDirs() []string {
+	output, err := exec.Command("python"+pythonVersion+"-config", "--prefix").Output()
+	if err != nil {
+		return nil
+	}
+	prefix := strings.TrimSpace(string(output))
+	if prefix == "" {
+		return nil
+	}
+	return []string{filepath.Join(prefix, "lib")}
+}
+
+// windowsRegistryInstallPaths returns Python install directories registered
+// in the Windows registry, queried with reg.exe to avoid a cgo/syscall dependency
+func windowsRegistryInstallPaths() []string {
+	var paths []string
+	for _, root := range []string{"HKCU", "HKLM"} {
+		key := root + `\Software\Python\PythonCore\` + pythonVersion + `\InstallPath`
+		output, err := exec.Command("reg", "query", key, "/ve").Output()
+		if err != nil {
+			continue
+		}
+		if path, err := parseRegDefaultValue(string(output)); err == nil {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// parseRegDefaultValue extracts the data of the default value from reg query output
+func parseRegDefaultValue(output string) (string, error) {
+	for _, line := range strings.Split(output, "\n") {
+		if _, data, found := strings.Cut(line, "REG_SZ"); found {
+			return strings.TrimSpace(data), nil
+		}
+	}
+	return "", errors.New("no REG_SZ value in registry output")
+}
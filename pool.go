@@ -0,0 +1,280 @@
+package gopython
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrPoolClosed is returned by calls on a Pool after Close or Finalize
+var ErrPoolClosed = errors.New("pool is closed")
+
+// Pool is a fixed set of sub-interpreters created with Py_NewInterpreter.
+// Each sub-interpreter has its own imported modules, sys.path and __main__
+// namespace, and each call runs in whichever one is idle, so state a request
+// leaves behind is never seen by calls running in the others or in the main
+// interpreter.
+//
+// Up to Python 3.11 all interpreters share one GIL: the pool isolates state
+// but does not run CPU-bound Python in parallel. With SetTrueGIL, calls in
+// different sub-interpreters overlap while they wait in C with the GIL
+// released (I/O, time.sleep), as calls to the main interpreter do.
+//
+// Known sub-interpreter caveats apply:
+//   - C extensions using single-phase initialization (older extensions,
+//     NumPy and others) may fail to import or misbehave in a sub-interpreter
+//   - Threads started by Python code must finish before the pool is closed
+//   - Go callbacks registered with RegisterCallback belong to the main
+//     interpreter and must not be called from pooled code
+type Pool struct {
+	py      *PureGoPython
+	size    int
+	free    chan uintptr // Thread states of idle sub-interpreters
+	closing chan struct{}
+
+	mu     sync.Mutex
+	closed bool
+	ended  bool
+	states []uintptr
+}
+
+// NewPool creates a pool of size sub-interpreters. Pools still open are
+// closed by Finalize.
+//
+// Example:
+//
+//	pool, err := py.NewPool(4)
+//	defer pool.Close()
+//	pool.RunEach("import handlers")
+//	result, err := pool.CallFunction("handlers", "handle", request)
+func (py *PureGoPython) NewPool(size int) (*Pool, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+	if size <= 0 {
+		return nil, fmt.Errorf("pool size must be positive, got %d", size)
+	}
+	if err := py.requireFeature("sub-interpreters"); err != nil {
+		return nil, err
+	}
+
+	p := &Pool{
+		py:      py,
+		size:    size,
+		free:    make(chan uintptr, size),
+		closing: make(chan struct{}),
+	}
+
+	err := py.withGIL(func() error {
+		current := py.pyThreadStateGet()
+		for i := 0; i < size; i++ {
+			// Py_NewInterpreter makes the new thread state current
+			state := py.pyNewInterpreter()
+			py.pyThreadStateSwap(current)
+			if state == 0 {
+				p.endInterpreters()
+				return fmt.Errorf("failed to create sub-interpreter %d of %d", i+1, size)
+			}
+			p.states = append(p.states, state)
+		}
+		py.pools = append(py.pools, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, state := range p.states {
+		p.free <- state
+	}
+	return p, nil
+}
+
+// Size returns the number of sub-interpreters in the pool
+func (p *Pool) Size() int {
+	return p.size
+}
+
+// CallFunction calls a Python function like PureGoPython.CallFunction in an
+// idle sub-interpreter, waiting for one to become available. The module is
+// imported into that sub-interpreter on first use.
+func (p *Pool) CallFunction(module, function string, args ...interface{}) (interface{}, error) {
+	return p.run(func(py *PureGoPython) (interface{}, error) {
+		moduleObj, err := py.importModule(module)
+		if err != nil {
+			return nil, err
+		}
+		defer py.safeDecRef(moduleObj)
+
+		functionObj := py.getAttrString(moduleObj, function)
+		if functionObj == 0 {
+			return nil, fmt.Errorf("%w: '%s' in module '%s'", ErrFunctionNotFound, function, module)
+		}
+		defer py.safeDecRef(functionObj)
+
+		resultObj, err := py.callObject(functionObj, args...)
+		if err != nil {
+			return nil, err
+		}
+		defer py.safeDecRef(resultObj)
+
+		return py.pythonToGo(PyObject(resultObj))
+	})
+}
+
+// RunEach executes code in the __main__ namespace of every sub-interpreter,
+// e.g. to import modules or define functions before serving calls. It waits
+// until every sub-interpreter is idle.
+func (p *Pool) RunEach(code string) error {
+	states := make([]uintptr, 0, p.size)
+	defer func() {
+		for _, state := range states {
+			p.free <- state
+		}
+	}()
+	for len(states) < p.size {
+		state, err := p.acquire()
+		if err != nil {
+			return err
+		}
+		states = append(states, state)
+	}
+
+	return p.py.withGIL(func() error {
+		for i, state := range states {
+			previous := p.py.pyThreadStateSwap(state)
+			err := p.py.runInMain(code)
+			p.py.pyThreadStateSwap(previous)
+			if err != nil {
+				return fmt.Errorf("failed to run code in sub-interpreter %d: %w", i+1, err)
+			}
+		}
+		return nil
+	})
+}
+
+// runInMain executes code in the current interpreter's __main__ namespace
+// without GIL management
+func (py *PureGoPython) runInMain(code string) error {
+	cCode, err := codeToCString(code)
+	if err != nil {
+		return err
+	}
+	globals, err := py.mainDict()
+	if err != nil {
+		return err
+	}
+	resultObj := py.pyRunStringFlags(cCode, pyFileInput, globals, globals, 0)
+	if resultObj == 0 {
+		return py.getPythonError()
+	}
+	py.safeDecRef(resultObj)
+	return nil
+}
+
+// Close waits for running calls to finish and ends the sub-interpreters.
+// Closing a pool twice is a no-op.
+func (p *Pool) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	close(p.closing)
+	p.mu.Unlock()
+
+	// Wait for every sub-interpreter to be returned
+	for i := 0; i < p.size; i++ {
+		<-p.free
+	}
+
+	if !p.py.IsInitialized() {
+		return nil
+	}
+	return p.py.withGIL(func() error {
+		p.endInterpreters()
+		return nil
+	})
+}
+
+// run executes fn with an idle sub-interpreter's thread state current and
+// the interpreter lock held
+func (p *Pool) run(fn func(py *PureGoPython) (interface{}, error)) (interface{}, error) {
+	py := p.py
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+
+	state, err := p.acquire()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { p.free <- state }()
+
+	return py.withGILReturn(func() (interface{}, error) {
+		previous := py.pyThreadStateSwap(state)
+		defer py.pyThreadStateSwap(previous)
+		return fn(py)
+	})
+}
+
+// acquire takes an idle sub-interpreter, waiting for one if all are busy
+func (p *Pool) acquire() (uintptr, error) {
+	select {
+	case state := <-p.free:
+		p.mu.Lock()
+		closed := p.closed
+		p.mu.Unlock()
+		if closed {
+			p.free <- state
+			return 0, ErrPoolClosed
+		}
+		return state, nil
+	case <-p.closing:
+		return 0, ErrPoolClosed
+	}
+}
+
+// endInterpreters ends the pool's sub-interpreters once. Must be called with
+// the GIL held.
+func (p *Pool) endInterpreters() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ended {
+		return
+	}
+	p.ended = true
+
+	py := p.py
+	current := py.pyThreadStateGet()
+	for _, state := range p.states {
+		// Py_EndInterpreter needs the sub-interpreter's thread state
+		// current and leaves no thread state current
+		py.pyThreadStateSwap(state)
+		py.pyEndInterpreter(state)
+	}
+	py.pyThreadStateSwap(current)
+	p.states = nil
+
+	for i, pool := range py.pools {
+		if pool == p {
+			py.pools = append(py.pools[:i], py.pools[i+1:]...)
+			break
+		}
+	}
+}
+
+// endPools ends the sub-interpreters of pools that were not closed, ahead of
+// finalization. Must be called with the GIL held.
+func (py *PureGoPython) endPools() {
+	for _, p := range append([]*Pool(nil), py.pools...) {
+		p.mu.Lock()
+		if !p.closed {
+			p.closed = true
+			close(p.closing)
+		}
+		p.mu.Unlock()
+		p.endInterpreters()
+	}
+}
@@ -42,8 +42,8 @@
 // GIL state management for better reliability in embedded contexts.
 //
 // Supported Type Conversions:
-// Go → Python: string→str, int→int, float64→float, bool→bool, []interface{}→list, map[string]interface{}→dict
-// Python → Go: str→string, int→int64, float→float64, bool→bool, list→[]interface{}, dict→map[string]interface{}
+// Go → Python: string→str, int/intN/uint/uintN→int, *big.Int→int, float32/float64→float, complex64/complex128→complex, bool→bool, time.Duration→timedelta, time.Time→datetime (timezone-aware), UUID→uuid.UUID, []byte→bytes, ByteArray→bytearray, []interface{}→list, map[string]interface{}→dict, map[interface{}]interface{}→dict, typed slices/arrays→list, typed maps→dict, OrderedDictValue→dict, SetValue→set, FrozenSetValue→frozenset
+// Python → Go: str→string, int→int64 (*big.Int beyond 64 bits), float→float64, complex→complex128, bool/numpy.bool_→bool, timedelta→time.Duration, datetime/date/time→time.Time (naive as UTC), uuid.UUID→UUID, bytes/bytearray/memoryview→[]byte, list/tuple→[]interface{}, dict→map[string]interface{} (map[interface{}]interface{} for non-string keys), set/frozenset→[]interface{}, numpy.ndarray→[]int64/[]float64 (1-D numeric) or via tolist()
 package gopython
 
 // This file serves as the main public API interface.
@@ -55,6 +55,12 @@ package gopython
 // - interpreter.go: Python interpreter lifecycle management
 // - venv.go: Virtual environment support
 // - threading.go: Thread safety wrappers and concurrency utilities
+// - handle.go: Persistent Python object handles
+// - callbacks.go: Go functions exposed to Python as callables
+// - structs.go: Go struct ↔ Python keyword argument and dataclass mapping
+// - cache.go: Module and function lookup caching, prepared calls
+// - context.go: context.Context cancellation of running calls
+// - pool.go: Pools of isolated sub-interpreters
 //
 // This modular approach improves code organization and maintainability
 // while keeping the public API simple and focused.
@@ -74,6 +80,9 @@ package gopython
 //   // Windows
 //   py, err := gopython.NewPureGoPython("C:\\Python310\\python310.dll")
 //
+//   // Search common install locations (see DiscoverLibPython)
+//   py, err := gopython.NewPureGoPython("")
+//
 // The function loads the library, registers all CPython API functions, and validates
 // that critical functions are available. Returns an error if the library cannot be
 // loaded or if required functions are missing.
@@ -129,7 +138,8 @@ package gopython
 
 // RunFile executes Python code from a file. The file is validated for
 // existence before execution. Returns an error if the file doesn't exist
-// or if there are Python execution errors.
+// or if there are Python execution errors. The script runs with __main__
+// semantics: __file__ is set and its directory is added to sys.path.
 //
 // Example:
 //   if err := py.RunFile("script.py"); err != nil {
@@ -155,8 +165,8 @@ package gopython
 //   }
 //   result, err := py.CallFunction("mymodule", "process_data", data)
 //
-// Supported argument types: string, int, int64, float64, bool, []interface{}, map[string]interface{}
-// Supported return types: string, int64, float64, bool, []interface{}, map[string]interface{}, nil
+// Supported argument types: string, int, int8-int64, uint, uint8-uint64, *big.Int, float32, float64, bool, time.Duration, UUID, []byte, ByteArray, []interface{}, map[string]interface{}, typed slices, arrays and maps, OrderedDictValue, SetValue, FrozenSetValue
+// Supported return types: string, int64, *big.Int, float64, bool, time.Duration, UUID, []byte, []interface{}, []int64, []float64, map[string]interface{}, nil
 //
 // The function is thread-safe and can be called from multiple goroutines concurrently.
 
@@ -0,0 +1,481 @@
+package gopython
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Go struct fields map to Python names through the `py` tag, falling back to
+// the `json` tag and then the field name:
+//
+//	type Config struct {
+//	    LearningRate float64 `py:"learning_rate"`
+//	    Epochs       int     `py:"epochs,omitempty"`
+//	    Debug        bool    `json:"debug"`
+//	    internal     string  // unexported fields are ignored
+//	    Skipped      string  `py:"-"`
+//	}
+//
+// With omitempty, zero-valued fields are left out so the Python default
+// applies. When decoding, names are matched case-insensitively if there is
+// no exact match.
+
+// structField describes how a Go struct field maps to a Python name
+type structField struct {
+	name      string
+	index     int
+	omitEmpty bool
+}
+
+// structFields returns the exported fields of a struct type with their
+// Python names
+func structFields(t reflect.Type) []structField {
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // Unexported
+		}
+
+		tag, ok := field.Tag.Lookup("py")
+		if !ok {
+			tag = field.Tag.Get("json")
+		}
+		if tag == "-" {
+			continue
+		}
+
+		name, options, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, structField{
+			name:      name,
+			index:     i,
+			omitEmpty: strings.Contains(","+options+",", ",omitempty,"),
+		})
+	}
+	return fields
+}
+
+// structToMap converts a struct, or a pointer to one, into a map keyed by
+// Python field names whose values goToPython can convert
+func structToMap(data interface{}) (map[string]interface{}, error) {
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, errors.New("struct pointer is nil")
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a struct, got %T", data)
+	}
+
+	result := make(map[string]interface{})
+	for _, field := range structFields(v.Type()) {
+		fv := v.Field(field.index)
+		if field.omitEmpty && fv.IsZero() {
+			continue
+		}
+		value, err := normalizeGoValue(fv)
+		if err != nil {
+			return nil, fmt.Errorf("field '%s': %v", field.name, err)
+		}
+		result[field.name] = value
+	}
+	return result, nil
+}
+
+// normalizeGoValue turns a reflected Go value into one of the types handled
+// by goToPython: named numbers widen to int64/uint64/float64, structs become maps
+// and other slices and maps become []interface{} and map[string]interface{}
+func normalizeGoValue(v reflect.Value) (interface{}, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+
+	// Types goToPython handles directly, including named ones
+	switch value := v.Interface().(type) {
+	case string, int, int64, float64, complex128, bool, *big.Int, time.Duration, time.Time, []byte, ByteArray,
+		UUID, SetValue, FrozenSetValue, OrderedDictValue, *PyHandle, []interface{}, map[string]interface{}:
+		return value, nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return normalizeGoValue(v.Elem())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.Complex64, reflect.Complex128:
+		return v.Complex(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Struct:
+		return structToMap(v.Interface())
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil, nil
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			item, err := normalizeGoValue(v.Index(i))
+			if err != nil {
+				return nil, fmt.Errorf("item %d: %v", i, err)
+			}
+			items[i] = item
+		}
+		return items, nil
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("unsupported map key type: %s", v.Type().Key())
+		}
+		if v.IsNil() {
+			return nil, nil
+		}
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			item, err := normalizeGoValue(iter.Value())
+			if err != nil {
+				return nil, fmt.Errorf("key '%s': %v", iter.Key().String(), err)
+			}
+			m[iter.Key().String()] = item
+		}
+		return m, nil
+	}
+	return nil, fmt.Errorf("unsupported Go type: %s", v.Type())
+}
+
+// decodeValue stores a value produced by pythonToGo into target, converting
+// between compatible types: maps fill struct fields, slices such as
+// []interface{} or the []float64 of a NumPy array fill typed slices and
+// arrays of the same length (bytes into a [32]byte digest) and numbers convert between sized kinds when they fit
+func decodeValue(value interface{}, target reflect.Value) error {
+	if value == nil {
+		target.Set(reflect.Zero(target.Type()))
+		return nil
+	}
+
+	if target.Kind() == reflect.Ptr {
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+		return decodeValue(value, target.Elem())
+	}
+
+	source := reflect.ValueOf(value)
+	if source.Type().AssignableTo(target.Type()) {
+		target.Set(source)
+		return nil
+	}
+
+	switch target.Kind() {
+	case reflect.Struct:
+		if ordered, ok := value.(OrderedDictValue); ok {
+			value = orderedToMap(ordered)
+		}
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for _, field := range structFields(target.Type()) {
+			item, found := lookupKey(m, field.name)
+			if !found {
+				continue
+			}
+			if err := decodeValue(item, target.Field(field.index)); err != nil {
+				return fmt.Errorf("field '%s': %v", field.name, err)
+			}
+		}
+		return nil
+
+	case reflect.Map:
+		if ordered, ok := value.(OrderedDictValue); ok {
+			value = orderedToMap(ordered)
+		}
+		m, ok := value.(map[string]interface{})
+		if !ok || target.Type().Key().Kind() != reflect.String {
+			break
+		}
+		result := reflect.MakeMapWithSize(target.Type(), len(m))
+		for key, item := range m {
+			elem := reflect.New(target.Type().Elem()).Elem()
+			if err := decodeValue(item, elem); err != nil {
+				return fmt.Errorf("key '%s': %v", key, err)
+			}
+			result.SetMapIndex(reflect.ValueOf(key).Convert(target.Type().Key()), elem)
+		}
+		target.Set(result)
+		return nil
+
+	case reflect.Slice:
+		if source.Kind() != reflect.Slice {
+			break
+		}
+		result := reflect.MakeSlice(target.Type(), source.Len(), source.Len())
+		for i := 0; i < source.Len(); i++ {
+			if err := decodeValue(source.Index(i).Interface(), result.Index(i)); err != nil {
+				return fmt.Errorf("item %d: %v", i, err)
+			}
+		}
+		target.Set(result)
+		return nil
+
+	case reflect.Array:
+		if source.Kind() != reflect.Slice {
+			break
+		}
+		if source.Len() != target.Len() {
+			return fmt.Errorf("cannot decode %d items into %s", source.Len(), target.Type())
+		}
+		if source.Type().Elem() == target.Type().Elem() {
+			reflect.Copy(target, source)
+			return nil
+		}
+		for i := 0; i < source.Len(); i++ {
+			if err := decodeValue(source.Index(i).Interface(), target.Index(i)); err != nil {
+				return fmt.Errorf("item %d: %v", i, err)
+			}
+		}
+		return nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := value.(int64)
+		if !ok {
+			break
+		}
+		if target.OverflowInt(n) {
+			return fmt.Errorf("value %d overflows %s", n, target.Type())
+		}
+		target.SetInt(n)
+		return nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		var u uint64
+		switch n := value.(type) {
+		case int64:
+			if n < 0 {
+				return fmt.Errorf("value %d overflows %s", n, target.Type())
+			}
+			u = uint64(n)
+		case *big.Int:
+			if !n.IsUint64() {
+				return fmt.Errorf("value %s overflows %s", n, target.Type())
+			}
+			u = n.Uint64()
+		default:
+			return fmt.Errorf("cannot decode %T into %s", value, target.Type())
+		}
+		if target.OverflowUint(u) {
+			return fmt.Errorf("value %d overflows %s", u, target.Type())
+		}
+		target.SetUint(u)
+		return nil
+
+	case reflect.Float32, reflect.Float64:
+		switch n := value.(type) {
+		case float64:
+			target.SetFloat(n)
+			return nil
+		case int64:
+			target.SetFloat(float64(n))
+			return nil
+		}
+
+	case reflect.Complex64, reflect.Complex128:
+		switch n := value.(type) {
+		case complex128:
+			target.SetComplex(n)
+			return nil
+		case float64:
+			target.SetComplex(complex(n, 0))
+			return nil
+		case int64:
+			target.SetComplex(complex(float64(n), 0))
+			return nil
+		}
+
+	case reflect.String:
+		if source.Kind() == reflect.String {
+			target.SetString(source.String())
+			return nil
+		}
+	}
+
+	if source.Type().ConvertibleTo(target.Type()) && source.Kind() == target.Kind() {
+		target.Set(source.Convert(target.Type()))
+		return nil
+	}
+	return fmt.Errorf("cannot decode %T into %s", value, target.Type())
+}
+
+// DecodeResult stores a value returned by CallFunction into the value
+// target points to, in the spirit of json.Unmarshal. Dicts fill struct fields
+// matched by py tag, json tag or name, lists fill typed slices and numbers
+// convert between sized types when they fit. Nested values are decoded
+// recursively.
+//
+// Example:
+//
+//	type Stats struct {
+//	    Mean  float64   `py:"mean"`
+//	    Count int       `py:"count"`
+//	    Tags  []string  `py:"tags"`
+//	}
+//	result, err := py.CallFunction("analysis", "summarize", data)
+//	var stats Stats
+//	err = gopython.DecodeResult(result, &stats)
+func DecodeResult(result interface{}, target interface{}) error {
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("target must be a non-nil pointer, got %T", target)
+	}
+	return decodeValue(result, v.Elem())
+}
+
+// lookupKey finds name in m, falling back to a case-insensitive match
+func lookupKey(m map[string]interface{}, name string) (interface{}, bool) {
+	if value, ok := m[name]; ok {
+		return value, true
+	}
+	for key, value := range m {
+		if strings.EqualFold(key, name) {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// orderedToMap converts an OrderedDictValue to a plain map
+func orderedToMap(entries OrderedDictValue) map[string]interface{} {
+	m := make(map[string]interface{}, len(entries))
+	for _, entry := range entries {
+		m[entry.Key] = entry.Value
+	}
+	return m
+}
+
+// dataclassHelper converts a dataclass instance, including nested
+// dataclasses, to a dict
+const dataclassHelper = `
+import dataclasses
+
+def _gopython_asdict(obj):
+    if not dataclasses.is_dataclass(obj) or isinstance(obj, type):
+        raise TypeError(f"expected a dataclass instance, got {type(obj).__name__}")
+    return dataclasses.asdict(obj)
+`
+
+// CallDataclass constructs an instance of a Python dataclass (or any class
+// accepting keyword arguments) from a Go struct, passing each exported field
+// as a keyword argument named by its py or json tag. data may also be a
+// map[string]interface{}. The instance is returned as a handle.
+//
+// Example:
+//
+//	type TrainConfig struct {
+//	    LearningRate float64 `py:"learning_rate"`
+//	    Epochs       int     `py:"epochs,omitempty"`
+//	}
+//	cfg, err := py.CallDataclass("trainer", "TrainConfig", TrainConfig{LearningRate: 0.01})
+//	defer cfg.Close()
+func (py *PureGoPython) CallDataclass(module, class string, data interface{}) (*PyHandle, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+
+	kwargs, ok := data.(map[string]interface{})
+	if !ok {
+		var err error
+		kwargs, err = structToMap(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %T to keyword arguments: %v", data, err)
+		}
+	}
+
+	result, err := py.withGILReturn(func() (interface{}, error) {
+		classObj, err := py.lookupFunction(module, class)
+		if err != nil {
+			return nil, err
+		}
+		defer py.safeDecRef(classObj)
+
+		instance, err := py.callObjectKwargs(classObj, nil, kwargs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create instance of '%s': %w", class, err)
+		}
+		return py.newHandle(instance), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result.(*PyHandle), nil
+}
+
+// CallFunctionStructKwargs calls a Python function with the exported fields
+// of a Go struct as keyword arguments, named by their py or json tags. Fields
+// tagged omitempty are left out when zero, so the Python defaults apply.
+//
+// Example:
+//
+//	type PlotOptions struct {
+//	    Title  string  `py:"title,omitempty"`
+//	    Width  float64 `py:"width,omitempty"`
+//	    Legend bool    `py:"legend"`
+//	}
+//	result, err := py.CallFunctionStructKwargs("charts", "plot", PlotOptions{Title: "Sales"})
+func (py *PureGoPython) CallFunctionStructKwargs(module, function string, kwargs interface{}) (interface{}, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+
+	kwargsMap, err := structToMap(kwargs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert %T to keyword arguments: %v", kwargs, err)
+	}
+	return py.CallFunctionKwargs(module, function, nil, kwargsMap)
+}
+
+// UnmarshalDataclass fills the struct pointed to by target from the fields of
+// the dataclass instance referenced by the handle, the reverse of
+// CallDataclass. Nested dataclasses decode into nested structs.
+func (py *PureGoPython) UnmarshalDataclass(h *PyHandle, target interface{}) error {
+	if !py.IsInitialized() {
+		return ErrNotInitialized
+	}
+	if err := checkHandle(h); err != nil {
+		return err
+	}
+	v := reflect.ValueOf(target)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return fmt.Errorf("target must be a non-nil pointer, got %T", target)
+	}
+
+	fields, err := py.withGILReturn(func() (interface{}, error) {
+		dict, err := py.callHelper(dataclassHelper, "_gopython_asdict", h)
+		if err != nil {
+			return nil, err
+		}
+		defer py.safeDecRef(dict)
+		return py.pythonDictToMap(PyObject(dict), &decodeOptions{}, 0)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read dataclass fields: %w", err)
+	}
+
+	return decodeValue(fields, v.Elem())
+}
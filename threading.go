@@ -1,17 +1,248 @@
 package gopython
 
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+)
+
+// ErrPanicRecovered is returned instead of a panic when SetRecoverPanics is
+// enabled
+var ErrPanicRecovered = errors.New("recovered from panic")
+
 // withGIL executes a function with GIL protection (thread-safe)
 func (py *PureGoPython) withGIL(fn func() error) error {
-	py.mu.Lock()
-	defer py.mu.Unlock()
+	if py.trueGIL {
+		if py.isClosed() {
+			return ErrClosed
+		}
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		state := py.pyGILStateEnsure()
+		defer py.pyGILStateRelease(state)
+		return py.run(fn)
+	}
+
+	unlock, err := py.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return py.run(fn)
+}
+
+// run calls fn, recovering panics if SetRecoverPanics is enabled. The
+// setting is read here, while the call holds the interpreter, like the
+// conversion settings.
+func (py *PureGoPython) run(fn func() error) error {
+	if py.recoverPanics {
+		return py.guarded(fn)()
+	}
 	return fn()
 }
 
+// updateSettings runs fn, which changes settings read during calls, holding
+// what those calls hold: the GIL once the interpreter runs with SetTrueGIL,
+// and the interpreter mutex otherwise. It returns ErrClosed after Close.
+func (py *PureGoPython) updateSettings(fn func()) error {
+	if py.trueGIL && py.IsInitialized() {
+		return py.withGIL(func() error {
+			fn()
+			return nil
+		})
+	}
+
+	unlock, err := py.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	fn()
+	return nil
+}
+
+// lock acquires the interpreter mutex and returns the function that releases
+// it, or ErrClosed once Close has unloaded libpython. The mutex is reentrant
+// for the OS thread holding it: Go callbacks run on the thread that called
+// into Python, so a callback that calls back into the library (e.g.
+// CallFunction) proceeds instead of deadlocking. The goroutine stays locked
+// to its OS thread while the mutex is held.
+func (py *PureGoPython) lock() (func(), error) {
+	runtime.LockOSThread()
+	py.closeMu.RLock()
+	if py.closed {
+		py.closeMu.RUnlock()
+		runtime.UnlockOSThread()
+		return nil, ErrClosed
+	}
+	ident := py.pyThreadGetThreadIdent()
+	py.closeMu.RUnlock()
+	if atomic.LoadUint64(&py.lockOwner) == ident {
+		return runtime.UnlockOSThread, nil
+	}
+
+	py.mu.Lock()
+	// Close may have unloaded libpython while this call waited
+	if py.isClosed() {
+		py.mu.Unlock()
+		runtime.UnlockOSThread()
+		return nil, ErrClosed
+	}
+	atomic.StoreUint64(&py.lockOwner, ident)
+	return func() {
+		atomic.StoreUint64(&py.lockOwner, 0)
+		py.mu.Unlock()
+		runtime.UnlockOSThread()
+	}, nil
+}
+
+// isClosed reports whether Close has unloaded libpython
+func (py *PureGoPython) isClosed() bool {
+	py.closeMu.RLock()
+	defer py.closeMu.RUnlock()
+	return py.closed
+}
+
+// guarded wraps fn so that a panic inside it is returned as an error. Any
+// Python exception left pending by the interrupted call is cleared.
+func (py *PureGoPython) guarded(fn func() error) func() error {
+	return func() (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				if py.pyErrOccurred() != 0 {
+					py.pyErrClear()
+				}
+				err = fmt.Errorf("%w: %v", ErrPanicRecovered, r)
+			}
+		}()
+		return fn()
+	}
+}
+
+// SetRecoverPanics makes every call recover from Go panics raised while it
+// holds the interpreter, such as a bad pointer surfacing through purego
+// during a conversion, and return them as errors wrapping ErrPanicRecovered
+// instead of crashing the program. Faults inside C code (segmentation
+// violations in libpython or an extension module) cannot be recovered; use
+// SafeCall to get a Python traceback for those. Disabled by default.
+func (py *PureGoPython) SetRecoverPanics(enabled bool) error {
+	return py.updateSettings(func() {
+		py.recoverPanics = enabled
+	})
+}
+
 // withGILReturn executes a function with GIL protection and returns a value (thread-safe)
 func (py *PureGoPython) withGILReturn(fn func() (interface{}, error)) (interface{}, error) {
-	py.mu.Lock()
-	defer py.mu.Unlock()
-	return fn()
+	var result interface{}
+	err := py.withGIL(func() error {
+		var err error
+		result, err = fn()
+		return err
+	})
+	return result, err
+}
+
+// SetTrueGIL switches from the default mutex-based serialization to real GIL
+// management. The GIL is released right after initialization and each call
+// acquires it with PyGILState_Ensure, holding it only for the duration of the
+// call. Python code that releases the GIL, such as socket, file or subprocess
+// I/O and time.sleep, then lets calls from other goroutines run concurrently,
+// and Python threads keep running between calls. CPU-bound Python code is
+// still serialized by the GIL itself.
+//
+// It must be called before Initialize or InitializeWithVenv.
+func (py *PureGoPython) SetTrueGIL(enabled bool) error {
+	if py.IsInitialized() {
+		return errors.New("GIL mode must be set before the interpreter is initialized")
+	}
+	if enabled {
+		if err := py.requireFeature("true GIL management"); err != nil {
+			return err
+		}
+	}
+	py.trueGIL = enabled
+	return nil
+}
+
+// releaseMainThread releases the GIL held by the initializing thread when
+// true GIL management is enabled. Py_Initialize creates the GIL itself since
+// Python 3.7, so PyEval_InitThreads is not needed.
+func (py *PureGoPython) releaseMainThread() {
+	if py.trueGIL && py.mainThreadState == 0 {
+		py.mainThreadState = py.pyEvalSaveThread()
+	}
+}
+
+// restoreMainThread reacquires the GIL with the thread state saved by
+// releaseMainThread, ahead of finalization
+func (py *PureGoPython) restoreMainThread() {
+	if py.mainThreadState != 0 {
+		py.pyEvalRestoreThread(py.mainThreadState)
+		py.mainThreadState = 0
+	}
+}
+
+// Task describes a unit of work sent to a worker started with StartWorker.
+// When Function is set the task calls Module.Function with Args, otherwise
+// it executes Code in __main__.
+type Task struct {
+	Module   string
+	Function string
+	Args     []interface{}
+	Code     string
+}
+
+// Result is the outcome of a Task. Value holds the converted return value of
+// a function call and is nil for code.
+type Result struct {
+	Task  Task
+	Value interface{}
+	Err   error
+}
+
+// workerQueueSize is the buffer size of the channels returned by StartWorker
+const workerQueueSize = 16
+
+// StartWorker starts a goroutine, locked to its own OS thread, that runs the
+// tasks sent on the returned task channel one at a time and delivers a Result
+// for each on the result channel, in the order the tasks were sent. Close the
+// task channel to stop the worker; the result channel is closed once the
+// remaining tasks have run. Both channels are buffered, but a caller sending
+// many tasks must keep reading results to avoid blocking the worker.
+//
+// Example:
+//
+//	tasks, results := py.StartWorker()
+//	go func() {
+//	    tasks <- gopython.Task{Module: "math", Function: "sqrt", Args: []interface{}{16.0}}
+//	    tasks <- gopython.Task{Code: "counter = 1"}
+//	    close(tasks)
+//	}()
+//	for result := range results {
+//	    fmt.Println(result.Value, result.Err)
+//	}
+func (py *PureGoPython) StartWorker() (chan<- Task, <-chan Result) {
+	tasks := make(chan Task, workerQueueSize)
+	results := make(chan Result, workerQueueSize)
+
+	go func() {
+		runtime.LockOSThread()
+		defer runtime.UnlockOSThread()
+		defer close(results)
+
+		for task := range tasks {
+			result := Result{Task: task}
+			if task.Function != "" {
+				result.Value, result.Err = py.CallFunction(task.Module, task.Function, task.Args...)
+			} else {
+				result.Err = py.RunString(task.Code)
+			}
+			results <- result
+		}
+	}()
+
+	return tasks, results
 }
 
 // Thread-safe wrapper functions for public API
@@ -33,24 +264,32 @@ func (py *PureGoPython) CallFunctionThreadSafe(module, function string, args ...
 
 // IsInitializedThreadSafe checks if Python interpreter is initialized (thread-safe)
 func (py *PureGoPython) IsInitializedThreadSafe() bool {
-	py.mu.Lock()
-	defer py.mu.Unlock()
+	unlock, err := py.lock()
+	if err != nil {
+		return false
+	}
+	defer unlock()
 	return py.IsInitialized()
 }
 
 // FinalizeThreadSafe shuts down the Python interpreter (thread-safe)
 func (py *PureGoPython) FinalizeThreadSafe() error {
-	py.mu.Lock()
-	defer py.mu.Unlock()
-	return py.Finalize()
+	return py.Finalize() // Already thread-safe internally
 }
 
-// Note: The library uses Go mutex-based thread safety instead of Python's GIL state management
+// Note: By default the library uses Go mutex-based thread safety instead of Python's GIL state management
 // This approach was chosen because:
-// 1. PyGILState_Ensure/Release caused fatal errors in embedded Python
+// 1. PyGILState_Ensure/Release caused fatal errors in embedded Python when the
+//    initializing thread kept holding the GIL
 // 2. Go mutex provides simpler and more reliable thread safety
 // 3. All Python operations are serialized through the mutex, preventing race conditions
+//    (the mutex is reentrant for its holding OS thread so Go callbacks can call back in)
 // 4. This is compatible with Python's threading model when called from embedded contexts
+//
+// SetTrueGIL opts into real GIL management instead: the GIL is released with
+// PyEval_SaveThread right after Py_Initialize and every call pins its goroutine
+// to an OS thread and holds the GIL via PyGILState_Ensure/Release. Go-side
+// state (callback registries, settings) is then protected by the GIL itself.
 
 // Future enhancement: If true parallel Python execution is needed, consider:
 // - Multiple sub-interpreters (PyInterpreterState)
@@ -71,4 +310,4 @@ func (py *PureGoPython) FinalizeThreadSafe() error {
 // ┌─────────────────────────────────────────────────────────────────┐
 // │                   Single Python Interpreter                    │
 // │                    (Thread-Safe Access)                       │
-// └─────────────────────────────────────────────────────────────────┘
\ No newline at end of file
+// └─────────────────────────────────────────────────────────────────┘
@@ -1,65 +1,278 @@
 package gopython
 
 import (
+	"fmt"
+	"runtime"
+	"strings"
 	"sync"
+	"unicode/utf16"
 	"unsafe"
 )
 
 // PyObject represents a Python object pointer
 type PyObject uintptr
 
+// pyBuffer mirrors CPython's Py_buffer, filled in by PyObject_GetBuffer and
+// released with PyBuffer_Release
+type pyBuffer struct {
+	buf        unsafe.Pointer
+	obj        uintptr
+	len        int
+	itemsize   int
+	readonly   int32
+	ndim       int32
+	format     *byte
+	shape      uintptr
+	strides    uintptr
+	suboffsets uintptr
+	internal   uintptr
+}
+
+// Start symbols for PyRun_StringFlags
+const (
+	pyEvalInput = 258 // Py_eval_input: a single expression
+	pyFileInput = 257 // Py_file_input: a sequence of statements
+)
+
+// UUID is a string in canonical form ("xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx")
+// that converts to and from Python's uuid.UUID. Plain Go strings are always
+// passed to Python as str.
+type UUID string
+
+// SetValue holds the elements of a Python set. Python sets convert to plain
+// []interface{} (in undefined order); wrap a slice in SetValue to pass it to
+// Python as a set.
+type SetValue []interface{}
+
+// FrozenSetValue holds the elements of a Python frozenset. Unlike a set, a
+// frozenset is hashable, so it can be a member of another set or a dict key
+// on the Python side.
+type FrozenSetValue []interface{}
+
+// KeyValue is a single entry of an OrderedDictValue
+type KeyValue struct {
+	Key   string
+	Value interface{}
+}
+
+// OrderedDictValue holds the entries of a Python dict in insertion order.
+// Dicts convert to it instead of map[string]interface{} when
+// SetPreserveDictOrder is enabled, and it converts back to a dict with the
+// same key order.
+type OrderedDictValue []KeyValue
+
+// OrderedKwargs holds keyword arguments for CallFunctionOrderedKwargs. The
+// kwargs dict the function receives has the keys in this order, which a Go
+// map cannot guarantee.
+type OrderedKwargs []KeyValue
+
+// ByteArray is a byte slice passed to Python as a mutable bytearray. When it
+// is passed directly as a call argument, changes Python makes to the
+// bytearray in place are copied back into the slice after the call returns.
+// Only the first len(slice) bytes are copied back if Python resizes it.
+type ByteArray []byte
+
+// UnknownValue stands in for a Python object that has no Go conversion when
+// SetReprFallback is enabled, keeping its type name and repr() for logging and
+// debugging. It cannot be converted back to Python.
+type UnknownValue struct {
+	TypeName string
+	Repr     string
+}
+
+// String returns the Python repr of the value
+func (u UnknownValue) String() string {
+	return u.Repr
+}
+
+// SkipUnconvertible configures how SetSkipUnconvertible handles list, tuple
+// and set items and dict values that have no Go conversion. The zero value
+// fails the whole conversion, as by default.
+type SkipUnconvertible struct {
+	Enabled       bool // Store a placeholder for unconvertible elements and keep converting
+	AsHandle      bool // Use a *PyHandle to the element as placeholder instead of nil
+	CollectErrors bool // Return the skipped elements' errors as ConversionErrors along with the result
+}
+
+// ConversionErrors lists why elements were skipped while converting a
+// container with SetSkipUnconvertible and CollectErrors enabled. It is
+// returned together with the partially converted value.
+type ConversionErrors []error
+
+// Error joins the messages of the skipped elements
+func (e ConversionErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return fmt.Sprintf("%d unconvertible elements skipped: %s", len(e), strings.Join(messages, "; "))
+}
+
+// Unwrap returns the errors of the skipped elements, for errors.Is and
+// errors.As
+func (e ConversionErrors) Unwrap() []error {
+	return e
+}
+
 // VirtualEnvConfig contains configuration for virtual environment initialization
 type VirtualEnvConfig struct {
 	VenvPath   string   // Path to virtual environment directory
 	SystemSite bool     // Include system site packages as fallback
 	SitePaths  []string // Additional site package directories
-	PythonHome string   // Python installation directory (optional)
+	PythonHome string   // Python installation directory holding the standard library (optional)
+	NoSite     bool     // Set sys.path directly without importing site (.pth files are not processed)
+}
+
+// InitConfig holds the startup options applied by InitializeWithConfig. The
+// zero value initializes like Initialize.
+type InitConfig struct {
+	Isolated          bool   // Isolated mode (-I): ignore PYTHON* environment variables and the user site directory
+	NoSite            bool   // Don't import site at startup (-S)
+	OptimizeLevel     int    // 1 removes asserts (-O), 2 also removes docstrings (-OO)
+	DontWriteBytecode bool   // Don't write .pyc files on import (-B)
+	ProgramName       string // Program name used to derive sys.executable and the prefixes (optional)
+}
+
+// PackageInfo describes an installed Python distribution
+type PackageInfo struct {
+	Name    string
+	Version string
+}
+
+// decodeOptions holds the options of a conversion from Python to Go. Values
+// returned to the caller follow the options set with SetIntAsPlatformInt and
+// friends, while the library converts its own helper results with the zero
+// value, so they always have the default types.
+type decodeOptions struct {
+	numericFallback   bool              // Convert array-like scalars via the number protocol
+	preserveDictOrder bool              // Convert dicts to OrderedDictValue instead of maps
+	intAsPlatformInt  bool              // Convert ints to Go int instead of int64
+	reprFallback      bool              // Convert unsupported objects to UnknownValue
+	skipUnconvertible SkipUnconvertible // Placeholders for unconvertible container elements
 }
 
 // PureGoPython represents a Python runtime instance with CPython API bindings
 type PureGoPython struct {
 	libHandle uintptr
 	mu        sync.Mutex // Thread safety protection
+	lockOwner uint64     // Thread ident of the mu holder, for reentrancy
+
+	// Set by Finalize so that finalizing again is a no-op
+	finalized bool
+
+	// Set by Close once libpython is unloaded. closeMu is held for reading
+	// around calls made into libpython outside mu, so Close can't unload it
+	// under them.
+	closed  bool
+	closeMu sync.RWMutex
+
+	// libpython functions that could not be registered
+	missingSymbols []string
+
+	// Sub-interpreter pools created by NewPool, ended by Finalize
+	pools []*Pool
+
+	// Virtual environment configured by InitializeWithVenv
+	venvPath string
+
+	// Callbacks registered with RegisterCallback, by name
+	callbacks map[string]int64
+
+	// Callbacks backing SetStdout/SetStderr, by stream name
+	streams map[string]int64
+
+	// Lookup caches, released by Finalize
+	modules       map[string]uintptr    // Imported modules, by name
+	preparedCalls map[callKey]*PyHandle // Functions resolved by Prepare
+	boundFuncs    map[string]*PyHandle  // Functions bound by DefineAndBind, by name
+	identityFunc  *PyHandle             // Identity function used by RoundTrip
+
+	// GIL state management enabled with SetTrueGIL
+	trueGIL         bool
+	mainThreadState uintptr // Thread state saved after initialization
+
+	// Conversion settings
+	maxConversionDepth int           // Maximum container nesting depth (0 = DefaultMaxConversionDepth)
+	floatRepr          bool          // Format float results of ResultAsString with repr
+	decode             decodeOptions // Options for values converted to Go for the caller
+
+	// Return panics raised during calls as errors, set with SetRecoverPanics
+	recoverPanics bool
 
 	// Core interpreter functions
 	pyInitialize     func()
 	pyFinalizeEx     func() int
 	pyIsInitialized  func() int
-	pySetProgramName func(*uint16)
-	pySetPythonHome  func(*uint16)
+	pySetProgramName func(unsafe.Pointer) // Takes a wchar_t string, see stringToWideCString
+	pySetPythonHome  func(unsafe.Pointer)
 	pySetPath        func(*uint16)
 
 	// Code execution functions
 	pyRunSimpleString func(*byte) int
+	pyRunStringFlags  func(*byte, int, uintptr, uintptr, uintptr) uintptr
+	pyCompileString   func(*byte, *byte, int) uintptr
 
 	// Module and import functions
-	pyImportImport      func(uintptr) uintptr
-	pyImportAddModule   func(*byte) uintptr
-	pyModuleGetDict     func(uintptr) uintptr
-	pyDictGetItemString func(uintptr, *byte) uintptr
+	pyImportImport        func(uintptr) uintptr
+	pyImportAddModule     func(*byte) uintptr
+	pyModuleGetDict       func(uintptr) uintptr
+	pyImportGetModuleDict func() uintptr
+	pyDictGetItemString   func(uintptr, *byte) uintptr
 
 	// Object attribute functions
-	pyObjectGetAttr     func(uintptr, uintptr) uintptr
-	pyObjectCallObject  func(uintptr, uintptr) uintptr
-	pyObjectType        func(uintptr) uintptr
-	pyObjectStr         func(uintptr) uintptr
-	pyObjectRepr        func(uintptr) uintptr
-	pyObjectGetTypeName func(uintptr) *byte
+	pyObjectGetAttr       func(uintptr, uintptr) uintptr
+	pyObjectGetAttrString func(uintptr, *byte) uintptr
+	pyObjectSetAttrString func(uintptr, *byte, uintptr) int
+	pyObjectHasAttrString func(uintptr, *byte) int
+	pyObjectCheckBuffer   func(uintptr) int
+	pyObjectGetBuffer     func(uintptr, *pyBuffer, int32) int32
+	pyBufferRelease       func(*pyBuffer)
+	pyObjectIsTrue        func(uintptr) int
+	pyCallableCheck       func(uintptr) int
+	pyObjectCallObject    func(uintptr, uintptr) uintptr
+	pyObjectCall          func(uintptr, uintptr, uintptr) uintptr
+	pyObjectType          func(uintptr) uintptr
+	pyObjectStr           func(uintptr) uintptr
+	pyObjectRepr          func(uintptr) uintptr
+	pyObjectGetTypeName   func(uintptr) *byte
 
 	// String/Unicode functions
-	pyUnicodeFromString func(*byte) uintptr
-	pyUnicodeAsUTF8     func(uintptr) *byte
+	pyUnicodeFromString        func(*byte) uintptr
+	pyUnicodeFromStringAndSize func(*byte, int) uintptr
+	pyUnicodeAsUTF8            func(uintptr) *byte
+	pyUnicodeAsUTF8AndSize     func(uintptr, *int) *byte
+	pyUnicodeAsEncodedString   func(uintptr, *byte, *byte) uintptr
+	pyUnicodeDecodeUTF8        func(*byte, int, *byte) uintptr
+
+	// Bytes functions
+	pyBytesFromStringAndSize     func(*byte, int) uintptr
+	pyBytesAsStringAndSize       func(uintptr, **byte, *int) int
+	pyBytesSize                  func(uintptr) int
+	pyByteArrayFromStringAndSize func(*byte, int) uintptr
+	pyByteArrayAsString          func(uintptr) *byte
+	pyByteArraySize              func(uintptr) int
 
 	// Integer functions
-	pyLongFromLong  func(int64) uintptr
-	pyLongAsLong    func(uintptr) int64
-	pyLongFromSize  func(int) uintptr
-	pyBoolFromLong  func(int64) uintptr
+	pyLongFromLong             func(int64) uintptr
+	pyLongFromUnsignedLongLong func(uint64) uintptr
+	pyLongAsLong               func(uintptr) int64
+	pyLongFromString           func(*byte, **byte, int) uintptr
+	pyLongFromSize             func(int) uintptr
+	pyBoolFromLong             func(int64) uintptr
 
 	// Float functions
 	pyFloatFromDouble func(float64) uintptr
 	pyFloatAsDouble   func(uintptr) float64
 
+	// Complex functions
+	pyComplexFromDoubles  func(float64, float64) uintptr
+	pyComplexRealAsDouble func(uintptr) float64
+	pyComplexImagAsDouble func(uintptr) float64
+
+	// Number protocol functions
+	pyNumberIndex func(uintptr) uintptr
+	pyNumberFloat func(uintptr) uintptr
+
 	// List functions
 	pyListNew     func(int) uintptr
 	pyListSetItem func(uintptr, int, uintptr) int
@@ -70,6 +283,9 @@ type PureGoPython struct {
 	pyDictNew           func() uintptr
 	pyDictSetItemString func(uintptr, *byte, uintptr) int
 	pyDictKeys          func(uintptr) uintptr
+	pyDictGetItem       func(uintptr, uintptr) uintptr
+	pyDictSetItem       func(uintptr, uintptr, uintptr) int
+	pyDictDelItemString func(uintptr, *byte) int
 
 	// Tuple functions
 	pyTupleNew     func(int) uintptr
@@ -77,20 +293,54 @@ type PureGoPython struct {
 	pyTupleGetItem func(uintptr, int) uintptr
 	pyTupleSize    func(uintptr) int
 
+	// Set functions
+	pySetNew       func(uintptr) uintptr
+	pySetAdd       func(uintptr, uintptr) int
+	pySetContains  func(uintptr, uintptr) int
+	pyFrozenSetNew func(uintptr) uintptr
+
+	// Iterator functions
+	pyObjectGetIter func(uintptr) uintptr
+	pyIterNext      func(uintptr) uintptr
+
 	// Type checking functions (using runtime type inspection - Python 3.10 compatible)
 
+	// Callable creation functions
+	pyCFunctionNewEx func(*pyMethodDef, uintptr, uintptr) uintptr
+
 	// Reference counting functions
 	pyIncRef func(uintptr)
 	pyDecRef func(uintptr)
 
 	// Error handling functions
-	pyErrOccurred func() uintptr
-	pyErrFetch    func(*uintptr, *uintptr, *uintptr)
-	pyErrClear    func()
+	pyErrOccurred           func() uintptr
+	pyErrFetch              func(*uintptr, *uintptr, *uintptr)
+	pyErrNormalizeException func(*uintptr, *uintptr, *uintptr)
+	pyErrClear              func()
+	pyErrSetString          func(uintptr, *byte)
+
+	// Global objects resolved from data symbols
+	pyNone                 uintptr // Py_None
+	pyTrue                 uintptr // Py_True
+	pyFalse                uintptr // Py_False
+	pyExcRuntimeError      uintptr // PyExc_RuntimeError
+	pyExcKeyboardInterrupt uintptr // PyExc_KeyboardInterrupt
 
-	// GIL functions (for future use if needed)
-	pyGILStateEnsure  func() int
-	pyGILStateRelease func(int)
+	// GIL functions (used when SetTrueGIL is enabled)
+	pyGILStateEnsure    func() int
+	pyGILStateRelease   func(int)
+	pyEvalSaveThread    func() uintptr
+	pyEvalRestoreThread func(uintptr)
+
+	// Thread functions
+	pyThreadGetThreadIdent   func() uint64
+	pyThreadStateSetAsyncExc func(uint64, uintptr) int
+
+	// Sub-interpreter functions (used by Pool)
+	pyNewInterpreter  func() uintptr
+	pyEndInterpreter  func(uintptr)
+	pyThreadStateGet  func() uintptr
+	pyThreadStateSwap func(uintptr) uintptr
 }
 
 // stringToCString converts a Go string to a null-terminated C string
@@ -104,13 +354,39 @@ func stringToCString(s string) *byte {
 	return (*byte)(unsafe.Pointer(&bytes[0]))
 }
 
-// uint16ToCWString converts a Go string to a null-terminated wide C string (UTF-16)
-func uint16ToCWString(s string) *uint16 {
-	runes := []rune(s)
-	utf16 := make([]uint16, len(runes)+1)
-	for i, r := range runes {
-		utf16[i] = uint16(r)
+// findNUL returns the 1-based line and offset of the first NUL byte in code,
+// or 0, 0 if there is none. Python source cannot contain NUL bytes, and as a
+// C string it would silently end at the first one.
+func findNUL(code string) (line, offset int) {
+	i := strings.IndexByte(code, 0)
+	if i < 0 {
+		return 0, 0
+	}
+	lineStart := strings.LastIndexByte(code[:i], '\n') + 1
+	return strings.Count(code[:i], "\n") + 1, i - lineStart + 1
+}
+
+// codeToCString converts Python source to a null-terminated C string. Unlike
+// stringToCString it returns an error for source containing a NUL byte
+// rather than a C string that would run only the code before it.
+func codeToCString(code string) (*byte, error) {
+	if line, offset := findNUL(code); line != 0 {
+		return nil, fmt.Errorf("source code contains a NUL byte at line %d, offset %d; write it as \\x00 in string literals", line, offset)
 	}
-	utf16[len(runes)] = 0
-	return (*uint16)(unsafe.Pointer(&utf16[0]))
-}
\ No newline at end of file
+	return stringToCString(code), nil
+}
+
+// stringToWideCString converts a Go string to a null-terminated wchar_t
+// string: UTF-16 on Windows, where wchar_t has 16 bits, and UTF-32 elsewhere
+func stringToWideCString(s string) unsafe.Pointer {
+	if runtime.GOOS == "windows" {
+		wide := append(utf16.Encode([]rune(s)), 0)
+		return unsafe.Pointer(&wide[0])
+	}
+	wide := make([]int32, 0, len(s)+1)
+	for _, r := range s {
+		wide = append(wide, r)
+	}
+	wide = append(wide, 0)
+	return unsafe.Pointer(&wide[0])
+}
@@ -9,6 +9,9 @@ import (
 
 // InitializeWithVenv initializes the Python interpreter with virtual environment support
 func (py *PureGoPython) InitializeWithVenv(config VirtualEnvConfig) error {
+	if py.isClosed() {
+		return ErrClosed
+	}
 	if py.pyInitialize == nil {
 		return errors.New("Python functions not registered")
 	}
@@ -18,14 +21,115 @@ func (py *PureGoPython) InitializeWithVenv(config VirtualEnvConfig) error {
 		return fmt.Errorf("virtual environment configuration failed: %v", err)
 	}
 
-	// Initialize Python interpreter
-	py.pyInitialize()
+	// Point the interpreter at the standard library of PythonHome.
+	// Py_SetPythonHome copies the string.
+	if config.PythonHome != "" {
+		py.pySetPythonHome(stringToWideCString(config.PythonHome))
+	}
+
+	// Initialize Python interpreter, without the implicit import of site
+	// when NoSite is set
+	if config.NoSite {
+		restore, err := py.setGlobalFlag("Py_NoSiteFlag", 1)
+		if err != nil {
+			return fmt.Errorf("NoSite is not supported: %v", err)
+		}
+		py.pyInitialize()
+		restore()
+	} else {
+		py.pyInitialize()
+	}
+	py.finalized = false
+	py.releaseMainThread()
 
 	// Configure virtual environment paths after initialization
 	if err := py.addSiteDirectories(config); err != nil {
-		return fmt.Errorf("failed to configure virtual environment paths: %v", err)
+		return fmt.Errorf("failed to configure virtual environment paths: %w", err)
 	}
 
+	py.venvPath = config.VenvPath
+	return nil
+}
+
+// pipHelper runs pip in-process via runpy, capturing its output. pip ends by
+// raising SystemExit, which carries its exit status.
+const pipHelper = `
+def _gopython_pip(args):
+    import contextlib, io, runpy, sys
+    output = io.StringIO()
+    saved_argv = getattr(sys, 'argv', None)
+    sys.argv = ['pip'] + list(args)
+    status = 0
+    try:
+        with contextlib.redirect_stdout(output), contextlib.redirect_stderr(output):
+            runpy.run_module('pip', run_name='__main__', alter_sys=True)
+    except SystemExit as e:
+        if isinstance(e.code, int):
+            status = e.code
+        elif e.code is not None:
+            output.write(str(e.code))
+            status = 1
+    finally:
+        if saved_argv is None:
+            del sys.argv
+        else:
+            sys.argv = saved_argv
+    return [status, output.getvalue()]
+`
+
+// InstallPackage installs a package with pip, running inside the embedded
+// interpreter. name may carry a version specifier, e.g. "requests>=2.31".
+// When the interpreter was initialized with InitializeWithVenv the package is
+// installed into that virtual environment. The error includes pip's output.
+func (py *PureGoPython) InstallPackage(name string) error {
+	if name == "" {
+		return errors.New("package name cannot be empty")
+	}
+	return py.runPip("install", name)
+}
+
+// InstallRequirements installs the packages listed in a requirements file with
+// pip, like InstallPackage
+func (py *PureGoPython) InstallRequirements(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("requirements file not accessible: %v", err)
+	}
+	return py.runPip("install", "-r", path)
+}
+
+// runPip runs pip with the given arguments, targeting the configured venv
+func (py *PureGoPython) runPip(args ...string) error {
+	if !py.IsInitialized() {
+		return ErrNotInitialized
+	}
+
+	pipArgs := []interface{}{}
+	for _, arg := range args {
+		pipArgs = append(pipArgs, arg)
+	}
+	pipArgs = append(pipArgs, "--disable-pip-version-check", "--no-input")
+	if py.venvPath != "" {
+		// Install into the venv layout rather than the base interpreter's prefix
+		pipArgs = append(pipArgs, "--prefix", py.venvPath)
+	}
+
+	result, err := py.withGILReturn(func() (interface{}, error) {
+		resultObj, err := py.callHelper(pipHelper, "_gopython_pip", pipArgs)
+		if err != nil {
+			return nil, err
+		}
+		defer py.safeDecRef(resultObj)
+		return py.internalToGo(PyObject(resultObj))
+	})
+	if err != nil {
+		return fmt.Errorf("failed to run pip: %w", err)
+	}
+
+	outcome := result.([]interface{})
+	status, output := outcome[0].(int64), outcome[1].(string)
+	if status != 0 {
+		return fmt.Errorf("pip %s failed with exit status %d:\n%s", args[0], status, output)
+	}
 	return nil
 }
 
@@ -41,9 +145,23 @@ func (py *PureGoPython) configureVirtualEnvironment(config VirtualEnvConfig) err
 	}
 
 	// Validate that it looks like a proper venv
-	venvLibDir := filepath.Join(config.VenvPath, "lib")
+	venvLibDir := GetVenvLibPath(config.VenvPath)
 	if _, err := os.Stat(venvLibDir); os.IsNotExist(err) {
-		return fmt.Errorf("invalid virtual environment: missing lib directory in %s", config.VenvPath)
+		return fmt.Errorf("invalid virtual environment: missing %s directory in %s", filepath.Base(venvLibDir), config.VenvPath)
+	}
+
+	if config.PythonHome != "" {
+		if _, err := os.Stat(config.PythonHome); err != nil {
+			return fmt.Errorf("invalid PythonHome: %v", err)
+		}
+		if err := py.requireFeature("path configuration"); err != nil {
+			return err
+		}
+	}
+
+	// Without site there is no getsitepackages() to find the system ones
+	if config.NoSite && config.SystemSite {
+		return errors.New("SystemSite requires site and cannot be combined with NoSite")
 	}
 
 	// All path configuration will be done after initialization using site.addsitedir()
@@ -51,77 +169,299 @@ func (py *PureGoPython) configureVirtualEnvironment(config VirtualEnvConfig) err
 	return nil
 }
 
+// siteHelper configures sys.path for a virtual environment and extra site
+// paths. Paths are passed as arguments rather than formatted into the source,
+// so quotes and backslashes in them are safe.
+const siteHelper = `
+import os
+import sys
+
+def _gopython_configure_site(venv_path, venv_bin, venv_site_packages, system_site, site_paths, no_site):
+    if venv_path:
+        # Set VIRTUAL_ENV for proper venv detection and put the venv's
+        # executables (bin, or Scripts on Windows) first on PATH
+        os.environ['VIRTUAL_ENV'] = venv_path
+        os.environ['PATH'] = venv_bin + os.pathsep + os.environ.get('PATH', '')
+
+    if venv_path and no_site:
+        # site was never imported, so sys.path holds only the stdlib: add
+        # the venv's site-packages as is, without processing .pth files
+        if venv_site_packages not in sys.path:
+            sys.path.append(venv_site_packages)
+    elif venv_path:
+        # Save essential Python paths (stdlib only) - platform independent
+        essential_paths = []
+        for path in sys.path:
+            # Keep only essential Python standard library paths, exclude site-packages.
+            # Windows installs keep the stdlib in <prefix>\Lib and extensions in <prefix>\DLLs
+            base = os.path.basename(path.rstrip('\\/')).lower()
+            if (path.endswith('python310.zip') or
+                path.endswith('python3.10') or
+                path.endswith('lib-dynload') or
+                (os.name == 'nt' and base in ('lib', 'dlls')) or
+                path == '') and 'site-packages' not in path:  # Only stdlib, no site-packages
+                essential_paths.append(path)
+
+        # Replace sys.path with clean virtual environment setup (without venv site-packages)
+        sys.path = essential_paths
+
+        # Process .pth files using site module - this will add venv_site_packages and process .pth files
+        import site
+        site.addsitedir(venv_site_packages, set())
+
+        # Add system site packages as fallback (SystemSite=True)
+        if system_site:
+            try:
+                for path in site.getsitepackages():
+                    if path not in sys.path:
+                        sys.path.append(path)
+            except Exception:
+                pass  # Ignore if getsitepackages() fails
+
+    # Add custom site paths to the beginning as well
+    for path in site_paths:
+        if path not in sys.path:
+            sys.path.insert(0, path)
+`
+
 // addSiteDirectories adds additional site directories after initialization
 func (py *PureGoPython) addSiteDirectories(config VirtualEnvConfig) error {
 	if len(config.SitePaths) == 0 && config.VenvPath == "" {
 		return nil
 	}
 
-	// Import required modules
-	siteCode := "import sys\nimport os\n"
-
-	// Configure virtual environment properly
+	// Use platform-aware site-packages detection
+	var venvBin, venvSitePackages string
 	if config.VenvPath != "" {
-		// Use platform-aware site-packages detection
-		venvSitePackages, err := GetVenvSitePackagesPath(config.VenvPath)
+		var err error
+		venvSitePackages, err = GetVenvSitePackagesPath(config.VenvPath)
 		if err != nil {
 			return fmt.Errorf("failed to locate venv site-packages: %v", err)
 		}
-		
-		// Set VIRTUAL_ENV environment variable for proper venv detection
-		siteCode += fmt.Sprintf("os.environ['VIRTUAL_ENV'] = r'%s'\n", config.VenvPath)
-
-		// Clean sys.path to only include essential paths
-		siteCode += fmt.Sprintf("venv_site_packages = r'%s'\n", venvSitePackages)
-		siteCode += `
-# Save essential Python paths (stdlib only) - platform independent
-essential_paths = []
-for path in sys.path:
-    # Keep only essential Python standard library paths, exclude site-packages
-    if (path.endswith('python310.zip') or 
-        path.endswith('python3.10') or 
-        path.endswith('lib-dynload') or
-        path == '') and 'site-packages' not in path:  # Only stdlib, no site-packages
-        essential_paths.append(path)
-
-# Replace sys.path with clean virtual environment setup (without venv site-packages)
-sys.path = essential_paths
-
-# Process .pth files using site module - this will add venv_site_packages and process .pth files
-import site
-site.addsitedir(venv_site_packages, set())
+		venvBin = GetVenvBinPath(config.VenvPath)
+	}
+
+	sitePaths := make([]interface{}, len(config.SitePaths))
+	for i, path := range config.SitePaths {
+		sitePaths[i] = path
+	}
+
+	// Execute the site configuration
+	return py.withGIL(func() error {
+		resultObj, err := py.callHelper(siteHelper, "_gopython_configure_site",
+			config.VenvPath, venvBin, venvSitePackages, config.SystemSite, sitePaths, config.NoSite)
+		if err != nil {
+			return fmt.Errorf("failed to configure site directories: %w", err)
+		}
+		py.safeDecRef(resultObj)
+		return nil
+	})
+}
+
+// installedPackagesHelper lists installed distributions as [name, version]
+// pairs, falling back to pkg_resources where importlib.metadata is missing.
+// A distribution installed in several sys.path entries, such as pip in a
+// venv and in the base installation, is listed once with the copy Python
+// imports: the first on sys.path, which is the venv's. Names are compared
+// normalized as in PEP 503, so Foo_Bar and foo-bar are the same distribution.
+const installedPackagesHelper = `
+def _gopython_installed_packages():
+    import re
+    try:
+        from importlib import metadata
+        found = {}
+        for dist in metadata.distributions():
+            name = dist.metadata['Name']
+            if not name:
+                continue
+            key = re.sub(r'[-_.]+', '-', name).lower()
+            if key not in found:
+                found[key] = [name, dist.version]
+        return list(found.values())
+    except ImportError:
+        import pkg_resources
+        return [[dist.project_name, dist.version] for dist in pkg_resources.working_set]
 `
-			
-			// Optionally add system site packages if SystemSite is True
-			if config.SystemSite {
-				siteCode += `
-# Add system site packages as fallback (SystemSite=True)
-import site
-try:
-    system_site_packages = site.getsitepackages()
-    for path in system_site_packages:
-        if path not in sys.path:
-            sys.path.append(path)
-except:
-    pass  # Ignore if getsitepackages() fails
+
+// InstalledPackages returns the name and version of every distribution
+// visible on the interpreter's sys.path. Each distribution is listed once,
+// with the version of the copy found first on sys.path, which is the venv's
+// when the interpreter runs in one.
+func (py *PureGoPython) InstalledPackages() ([]PackageInfo, error) {
+	if !py.IsInitialized() {
+		return nil, ErrNotInitialized
+	}
+
+	result, err := py.withGILReturn(func() (interface{}, error) {
+		resultObj, err := py.callHelper(installedPackagesHelper, "_gopython_installed_packages")
+		if err != nil {
+			return nil, err
+		}
+		defer py.safeDecRef(resultObj)
+		return py.internalToGo(PyObject(resultObj))
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed packages: %w", err)
+	}
+
+	var packages []PackageInfo
+	for _, item := range result.([]interface{}) {
+		pair := item.([]interface{})
+		name, _ := pair[0].(string)
+		version, _ := pair[1].(string)
+		packages = append(packages, PackageInfo{Name: name, Version: version})
+	}
+	return packages, nil
+}
+
+// checkRequirementHelper evaluates a PEP 508 requirement against the installed
+// distributions. packaging is not part of the standard library, so pip's
+// vendored copy is used when it is not installed.
+const checkRequirementHelper = `
+def _gopython_check_requirement(spec):
+    try:
+        from packaging.requirements import Requirement
+    except ImportError:
+        try:
+            from pip._vendor.packaging.requirements import Requirement
+        except ImportError:
+            raise RuntimeError("the 'packaging' package is required to check requirements")
+    from importlib import metadata
+    req = Requirement(spec)
+    if req.marker is not None and not req.marker.evaluate():
+        return True
+    try:
+        version = metadata.version(req.name)
+    except metadata.PackageNotFoundError:
+        return False
+    return req.specifier.contains(version, prereleases=True)
 `
+
+// CheckRequirement reports whether a PEP 508 requirement such as
+// "numpy>=1.20" is satisfied by the installed packages. A requirement whose
+// environment marker does not apply is treated as satisfied.
+func (py *PureGoPython) CheckRequirement(spec string) (bool, error) {
+	if !py.IsInitialized() {
+		return false, ErrNotInitialized
+	}
+	if spec == "" {
+		return false, errors.New("requirement cannot be empty")
+	}
+
+	result, err := py.withGILReturn(func() (interface{}, error) {
+		resultObj, err := py.callHelper(checkRequirementHelper, "_gopython_check_requirement", spec)
+		if err != nil {
+			return nil, err
+		}
+		defer py.safeDecRef(resultObj)
+		return py.internalToGo(PyObject(resultObj))
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to check requirement '%s': %w", spec, err)
+	}
+
+	satisfied, ok := result.(bool)
+	if !ok {
+		return false, fmt.Errorf("unexpected requirement check result: %T", result)
+	}
+	return satisfied, nil
+}
+
+// Prefixes returns sys.prefix and sys.base_prefix. They differ when a virtual
+// environment is active, so they show which environment the interpreter is
+// running against.
+func (py *PureGoPython) Prefixes() (prefix, basePrefix string, err error) {
+	if !py.IsInitialized() {
+		return "", "", ErrNotInitialized
+	}
+
+	err = py.withGIL(func() error {
+		sysModule, err := py.importModule("sys")
+		if err != nil {
+			return err
+		}
+		defer py.safeDecRef(sysModule)
+
+		values := make([]string, 2)
+		for i, name := range []string{"prefix", "base_prefix"} {
+			attr := py.getAttrString(sysModule, name)
+			if attr == 0 {
+				return fmt.Errorf("sys.%s is not set", name)
+			}
+			values[i], err = py.objectToString(attr)
+			py.safeDecRef(attr)
+			if err != nil {
+				return fmt.Errorf("failed to read sys.%s: %v", name, err)
 			}
+		}
+		prefix, basePrefix = values[0], values[1]
+		return nil
+	})
+	return prefix, basePrefix, err
+}
+
+// sysPathHelper adds and removes sys.path entries. Paths are passed as
+// arguments, so quotes and backslashes in them need no escaping.
+const sysPathHelper = `
+import sys
+
+def _gopython_add_sys_path(path):
+    while path in sys.path:
+        sys.path.remove(path)
+    sys.path.insert(0, path)
+
+def _gopython_remove_sys_path(path):
+    if path not in sys.path:
+        return False
+    while path in sys.path:
+        sys.path.remove(path)
+    return True
+`
+
+// AddSysPath makes the modules in dir importable by prepending it to
+// sys.path, without setting up a virtual environment. Relative paths are
+// resolved against the current working directory. Adding a directory that is
+// already present moves it to the front.
+//
+// Example:
+//
+//	exe, _ := os.Executable()
+//	py.AddSysPath(filepath.Join(filepath.Dir(exe), "python"))
+//	py.CallFunction("mymodule", "run")
+func (py *PureGoPython) AddSysPath(dir string) error {
+	return py.updateSysPath(dir, "_gopython_add_sys_path")
+}
+
+// RemoveSysPath removes every occurrence of dir from sys.path. It returns an
+// error if dir was not in sys.path.
+func (py *PureGoPython) RemoveSysPath(dir string) error {
+	return py.updateSysPath(dir, "_gopython_remove_sys_path")
+}
+
+// updateSysPath resolves dir and passes it to the named sysPathHelper function
+func (py *PureGoPython) updateSysPath(dir, function string) error {
+	if !py.IsInitialized() {
+		return ErrNotInitialized
+	}
+	if dir == "" {
+		return errors.New("path cannot be empty")
 	}
 
-	// Add custom site paths to the beginning as well
-	for _, path := range config.SitePaths {
-		siteCode += fmt.Sprintf("custom_path = r'%s'\n", path)
-		siteCode += "if custom_path not in sys.path:\n"
-		siteCode += "    sys.path.insert(0, custom_path)\n"
+	path, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("failed to resolve path %s: %v", dir, err)
 	}
 
-	// Execute the site configuration
 	return py.withGIL(func() error {
-		cCode := stringToCString(siteCode)
-		result := py.pyRunSimpleString(cCode)
-		if result != 0 {
-			return fmt.Errorf("failed to configure site directories")
+		resultObj, err := py.callHelper(sysPathHelper, function, path)
+		if err != nil {
+			return fmt.Errorf("failed to update sys.path: %w", err)
+		}
+		defer py.safeDecRef(resultObj)
+
+		if resultObj == py.pyFalse {
+			return fmt.Errorf("%s is not in sys.path", path)
 		}
 		return nil
 	})
-}
\ No newline at end of file
+}
@@ -0,0 +1,131 @@
+package gopython
+
+import (
+	"fmt"
+)
+
+// WrapperSpec describes the signature of a Python function, read with
+// inspect.signature, in enough detail for a code generator to emit a typed Go
+// wrapper for it
+type WrapperSpec struct {
+	Module   string
+	Function string
+	Doc      string      // Cleaned docstring from inspect.getdoc, empty if there is none
+	Params   []ParamSpec // Parameters in declaration order
+	Returns  string      // Return annotation, empty if there is none
+}
+
+// ParamSpec describes one parameter of a Python function
+type ParamSpec struct {
+	Name string
+	// Kind is the inspect.Parameter kind: POSITIONAL_ONLY,
+	// POSITIONAL_OR_KEYWORD, VAR_POSITIONAL, KEYWORD_ONLY or VAR_KEYWORD
+	Kind       string
+	Annotation string      // Annotation as written in Python, empty if there is none
+	HasDefault bool        // Whether the parameter has a default value
+	Default    interface{} // Default converted to Go, nil if it has no Go conversion
+	// DefaultRepr is the repr() of the default, for defaults without a Go
+	// conversion such as sentinels and functions
+	DefaultRepr string
+}
+
+// wrapperSpecHelper reads the signature of a callable. Annotations are
+// formatted the way inspect shows them, so typing.Optional[str] reads
+// "Optional[str]" and classes from other modules keep their module name.
+// String annotations, such as those under "from __future__ import
+// annotations", are returned unchanged. Defaults are returned separately
+// because they may not have a Go conversion.
+const wrapperSpecHelper = `
+import inspect
+
+def _gopython_annotation(annotation):
+    if annotation is inspect.Parameter.empty:
+        return None
+    if isinstance(annotation, str):
+        return annotation
+    return inspect.formatannotation(annotation)
+
+def _gopython_wrapper_spec(fn):
+    signature = inspect.signature(fn)
+    params = []
+    defaults = []
+    for param in signature.parameters.values():
+        has_default = param.default is not inspect.Parameter.empty
+        params.append([param.name, param.kind.name, _gopython_annotation(param.annotation),
+                       has_default, repr(param.default) if has_default else ""])
+        defaults.append(param.default if has_default else None)
+    spec = [inspect.getdoc(fn), _gopython_annotation(signature.return_annotation), params]
+    return (spec, defaults)
+`
+
+// GenerateWrapperSpec introspects a Python function with inspect and returns
+// its parameter names, kinds, annotations and defaults, along with its return
+// annotation and docstring. It is meant for tools generating typed Go
+// bindings for a Python library. Annotations are returned as strings, since
+// they need not name Go-convertible types. Functions without a readable
+// signature, such as some builtins, return an error.
+//
+// Example:
+//
+//	spec, err := py.GenerateWrapperSpec("trainer", "fit")
+//	for _, param := range spec.Params {
+//	    fmt.Printf("%s %s (default %s)\n", param.Name, param.Annotation, param.DefaultRepr)
+//	}
+func (py *PureGoPython) GenerateWrapperSpec(module, function string) (WrapperSpec, error) {
+	if !py.IsInitialized() {
+		return WrapperSpec{}, ErrNotInitialized
+	}
+
+	result, err := py.withGILReturn(func() (interface{}, error) {
+		functionObj, err := py.lookupFunction(module, function)
+		if err != nil {
+			return nil, err
+		}
+		fnHandle := py.newHandle(functionObj)
+		defer fnHandle.drop()
+
+		resultObj, err := py.callHelper(wrapperSpecHelper, "_gopython_wrapper_spec", fnHandle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to inspect '%s' in module '%s': %w", function, module, err)
+		}
+		defer py.safeDecRef(resultObj)
+		return py.wrapperSpecToGo(resultObj, module, function)
+	})
+	if err != nil {
+		return WrapperSpec{}, err
+	}
+	return result.(WrapperSpec), nil
+}
+
+// wrapperSpecToGo converts the (spec, defaults) tuple returned by
+// wrapperSpecHelper without GIL management
+func (py *PureGoPython) wrapperSpecToGo(resultObj uintptr, module, function string) (WrapperSpec, error) {
+	// PyTuple_GetItem and PyList_GetItem return borrowed references
+	value, err := py.internalToGo(PyObject(py.pyTupleGetItem(resultObj, 0)))
+	if err != nil {
+		return WrapperSpec{}, fmt.Errorf("failed to convert signature of '%s': %v", function, err)
+	}
+	defaults := py.pyTupleGetItem(resultObj, 1)
+
+	fields := value.([]interface{})
+	spec := WrapperSpec{Module: module, Function: function}
+	spec.Doc, _ = fields[0].(string)
+	spec.Returns, _ = fields[1].(string)
+	for i, item := range fields[2].([]interface{}) {
+		param := item.([]interface{})
+		p := ParamSpec{Name: param[0].(string), Kind: param[1].(string)}
+		p.Annotation, _ = param[2].(string)
+		p.HasDefault, _ = param[3].(bool)
+		p.DefaultRepr, _ = param[4].(string)
+		if p.HasDefault {
+			if p.Default, err = py.internalToGo(PyObject(py.pyListGetItem(defaults, i))); err != nil {
+				p.Default = nil
+				if py.pyErrOccurred() != 0 {
+					py.pyErrClear()
+				}
+			}
+		}
+		spec.Params = append(spec.Params, p)
+	}
+	return spec, nil
+}